@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -32,10 +34,26 @@ type Config struct {
 	FrontendURL string
 
 	// ICS Import
-	ICSBaseURL string
+	ICSBaseURL              string
+	ICSFetchWorkers         int // Parallel downloads per import run
+	ICSFetchPerHostCap      int // Max parallel requests against the same host
+	ICSDiscoveryHours       int // Probe all semester files of a zenturie every N hours, otherwise only known files
+	ICSRecurrencePastDays   int // Recurring events are expanded from now-PastDays ...
+	ICSRecurrenceFutureDays int // ... to now+FutureDays
+	ICSFetchRetries         int // Extra attempts of a failed download within a run
+	ICSFetchRetryDelay      int // Seconds before the first retry, doubled for every further one
+	ICSBreakerThreshold     int // Failed runs in a row after which a feed URL is paused
+	ICSBreakerCooldown      int // Minutes a paused feed URL is skipped
+
+	// Feed sources
+	FeedSourceDir string // local_dir and json feed sources may only read files below this directory
 
 	// Logging
-	LogLevel string // debug, info, warning, error
+	LogLevel         string // debug, info, warning, error
+	LogRetentionDays int    // Rotated log files older than this are deleted by the retention job
+
+	// Cluster
+	InstanceID string // Identifies this replica, e.g. as holder of a scheduler job lock
 }
 
 var AppConfig *Config
@@ -68,11 +86,55 @@ func LoadConfig() *Config {
 		FrontendURL: getEnvConfig("FRONTEND_URL", "https://nora-nak.de"),
 		ICSBaseURL:  getEnvConfig("ICS_BASE_URL", "https://cis.nordakademie.de/fileadmin/Infos/Stundenplaene"),
 		LogLevel:    getEnvConfig("LOG_LEVEL", "info"),
+
+		LogRetentionDays: getEnvIntConfig("LOG_RETENTION_DAYS", 30),
+
+		ICSFetchWorkers:    getEnvIntConfig("ICS_FETCH_WORKERS", 8),
+		ICSFetchPerHostCap: getEnvIntConfig("ICS_FETCH_PER_HOST", 4),
+		ICSDiscoveryHours:  getEnvIntConfig("ICS_DISCOVERY_HOURS", 24),
+
+		ICSRecurrencePastDays:   getEnvNonNegativeIntConfig("ICS_RECURRENCE_PAST_DAYS", 365),
+		ICSRecurrenceFutureDays: getEnvIntConfig("ICS_RECURRENCE_FUTURE_DAYS", 365),
+
+		ICSFetchRetries:     getEnvIntConfig("ICS_FETCH_RETRIES", 2),
+		ICSFetchRetryDelay:  getEnvIntConfig("ICS_FETCH_RETRY_DELAY", 2),
+		ICSBreakerThreshold: getEnvIntConfig("ICS_BREAKER_THRESHOLD", 5),
+		ICSBreakerCooldown:  getEnvIntConfig("ICS_BREAKER_COOLDOWN", 360),
+
+		FeedSourceDir: getEnvConfig("FEED_SOURCE_DIR", "/var/lib/nora/feeds"),
+
+		InstanceID: getEnvConfig("INSTANCE_ID", defaultInstanceID()),
 	}
 
 	return AppConfig
 }
 
+// DBApplicationPrefix prefixes the instance ID in the application_name of database connections
+const DBApplicationPrefix = "nora-backend@"
+
+// defaultInstanceID returns the host name, which is unique per container or pod
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "nora-" + strconv.Itoa(os.Getpid())
+	}
+	return hostname
+}
+
+// DBApplicationName returns the application_name of this instance's database connections
+// Postgres shows it in pg_stat_activity, which tells the instances apart.
+func DBApplicationName() string {
+	instanceID := defaultInstanceID()
+	if AppConfig != nil {
+		instanceID = AppConfig.InstanceID
+	}
+	name := DBApplicationPrefix + strings.Join(strings.Fields(instanceID), "-")
+	if len(name) > 63 { // NAMEDATALEN - 1
+		name = name[:63]
+	}
+	return name
+}
+
 // getEnvConfig retrieves environment variable or returns default value
 func getEnvConfig(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -81,3 +143,34 @@ func getEnvConfig(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvIntConfig retrieves a positive integer environment variable or returns default value
+func getEnvIntConfig(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvNonNegativeIntConfig is getEnvIntConfig for settings where 0 is a valid value
+func getEnvNonNegativeIntConfig(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetJobEnv returns the schedule and enable flag of a scheduler job from the environment
+// JOB_<NAME>_SCHEDULE is a cron expression, JOB_<NAME>_ENABLED is true or false (name upper-cased).
+// Empty values (and enabled == nil) mean the variable is not set.
+func GetJobEnv(name string) (schedule string, enabled *bool) {
+	prefix := "JOB_" + strings.ToUpper(name) + "_"
+	schedule = strings.TrimSpace(os.Getenv(prefix + "SCHEDULE"))
+
+	if value, err := strconv.ParseBool(os.Getenv(prefix + "ENABLED")); err == nil {
+		enabled = &value
+	}
+	return schedule, enabled
+}
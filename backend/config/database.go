@@ -24,8 +24,8 @@ func InitDatabase() error {
 	dbname := getEnv("DB_NAME", "nora")
 	sslmode := getEnv("DB_SSLMODE", "disable")
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=Europe/Berlin",
-		host, port, user, password, dbname, sslmode)
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=Europe/Berlin application_name=%s",
+		host, port, user, password, dbname, sslmode, DBApplicationName())
 
 	// Configure GORM logger
 	gormLogger := logger.Default.LogMode(logger.Info)
@@ -70,15 +70,36 @@ func AutoMigrate() error {
 	err := DB.AutoMigrate(
 		&models.Tenant{},
 		&models.Zenturie{},
+		&models.ZenturieSemester{},
 		&models.User{},
 		&models.Course{},
 		&models.Room{},
+		&models.Lecturer{},
 		&models.Timetable{},
+		&models.TimetableRoom{},
 		&models.CustomHour{},
 		&models.Exam{},
 		&models.Friend{},
 		&models.FriendRequest{},
 		&models.UserSettings{},
+		&models.CourseSelection{},
+		&models.FeedSource{},
+		&models.FeedFetchState{},
+		&models.FeedCircuitBreaker{},
+		&models.FeedUpload{},
+		&models.FeedSnapshotContent{},
+		&models.FeedSnapshot{},
+		&models.ExtractionRule{},
+		&models.RoomRule{},
+		&models.ImportRun{},
+		&models.ImportRunZenturieResult{},
+		&models.ImportRunFileResult{},
+		&models.ImportQuarantine{},
+		&models.SchedulerJob{},
+		&models.RetentionPolicy{},
+		&models.RetentionRun{},
+		&models.RetentionRunItem{},
+		&models.TimetableChange{},
 	)
 
 	if err != nil {
@@ -91,6 +112,10 @@ func AutoMigrate() error {
 	// Add unique constraint for friend_requests table (v2) - prevent duplicate requests
 	DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS unique_friend_request ON friend_requests(LEAST(requester_id, receiver_id), GREATEST(requester_id, receiver_id)) WHERE status IN ('pending', 'accepted')")
 
+	// Add unique constraint for course_selections table - one selection per course or event series
+	// (either course_id or summary is NULL, which a plain unique index would never match)
+	DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS unique_course_selection ON course_selections(user_id, zenturien_id, COALESCE(course_id, 0), COALESCE(summary, ''))")
+
 	// Migration: Drop old indexes and create tenant-scoped composite index
 	// This allows same UID across different tenants and zenturien
 	log.Println("Migrating timetable indexes for multi-tenancy support...")
@@ -99,82 +124,74 @@ func AutoMigrate() error {
 	DB.Exec("DROP INDEX IF EXISTS idx_uid_zenturie")
 	DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_uid_zenturie ON timetables(tenant_id, uid, zenturien_id)")
 
-	// Migration: Fix room numbers to exactly 4 characters (Letter + 3 digits)
-	// Removes trailing letters from room numbers like "A001E" -> "A001"
-	if err := fixRoomNumbers(); err != nil {
-		log.Printf("WARNING: Failed to fix room numbers: %v", err)
+	// Migration: Assign the primary room of existing events via the timetable_rooms join table
+	// Further rooms of multi-room events are added by the next import
+	if err := backfillTimetableRooms(); err != nil {
+		log.Printf("WARNING: Failed to backfill timetable rooms: %v", err)
+	}
+
+	// Migration: Seed feed source for the default tenant from ICS_BASE_URL
+	// Before feed sources were stored per tenant, all tenants used the global ICS_BASE_URL
+	if err := seedDefaultFeedSource(); err != nil {
+		log.Printf("WARNING: Failed to seed default feed source: %v", err)
 	}
 
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
-// fixRoomNumbers fixes room numbers to exactly 4 characters (Letter + 3 digits)
-// Removes trailing letters from room numbers like "A001E" -> "A001"
-// Handles duplicates by merging relationships to the existing room
-func fixRoomNumbers() error {
-	log.Println("Checking for room numbers that need fixing...")
-
-	// Find all rooms with room_number longer than 4 characters
-	var roomsToFix []models.Room
-	if err := DB.Where("LENGTH(room_number) > 4").Find(&roomsToFix).Error; err != nil {
-		return fmt.Errorf("failed to find rooms to fix: %w", err)
+// backfillTimetableRooms fills the empty timetable_rooms table from timetables.room_id
+func backfillTimetableRooms() error {
+	var count int64
+	if err := DB.Model(&models.TimetableRoom{}).Count(&count).Error; err != nil {
+		return err
 	}
-
-	if len(roomsToFix) == 0 {
-		log.Println("No room numbers need fixing")
+	if count > 0 {
 		return nil
 	}
 
-	log.Printf("Found %d room(s) with room_number longer than 4 characters", len(roomsToFix))
-
-	fixed := 0
-	merged := 0
-
-	for _, room := range roomsToFix {
-		// Truncate to 4 characters
-		newRoomNumber := room.RoomNumber
-		if len(newRoomNumber) > 4 {
-			newRoomNumber = newRoomNumber[:4]
-		}
-
-		log.Printf("Fixing room: %s -> %s", room.RoomNumber, newRoomNumber)
-
-		// Check if a room with the new number already exists
-		var existingRoom models.Room
-		result := DB.Where("room_number = ? AND id != ?", newRoomNumber, room.ID).First(&existingRoom)
-
-		if result.Error == nil {
-			// Room with truncated number already exists - merge relationships
-			log.Printf("Room %s already exists (ID: %d), merging room %s (ID: %d)",
-				newRoomNumber, existingRoom.ID, room.RoomNumber, room.ID)
-
-			// Update all timetables to reference the existing room
-			DB.Model(&models.Timetable{}).Where("room_id = ?", room.ID).Update("room_id", existingRoom.ID)
+	result := DB.Exec(`INSERT INTO timetable_rooms (timetable_id, room_id, position)
+		SELECT id, room_id, 0 FROM timetables WHERE room_id IS NOT NULL
+		ON CONFLICT DO NOTHING`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled %d timetable room assignments", result.RowsAffected)
+	}
+	return nil
+}
 
-			// Update all custom_hours to reference the existing room
-			DB.Model(&models.CustomHour{}).Where("room_id = ?", room.ID).Update("room_id", existingRoom.ID)
+// seedDefaultFeedSource creates the feed source of the default tenant if it has none yet
+func seedDefaultFeedSource() error {
+	var tenant models.Tenant
+	if err := DB.Where("slug = ?", AppConfig.DefaultTenantSlug).First(&tenant).Error; err != nil {
+		log.Printf("Default tenant '%s' not found, skipping feed source seed", AppConfig.DefaultTenantSlug)
+		return nil
+	}
 
-			// Update all exams to reference the existing room
-			DB.Model(&models.Exam{}).Where("room_id = ?", room.ID).Update("room_id", existingRoom.ID)
+	var count int64
+	if err := DB.Model(&models.FeedSource{}).Where("tenant_id = ?", tenant.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check feed source: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
 
-			// Delete the duplicate room
-			if err := DB.Delete(&room).Error; err != nil {
-				log.Printf("WARNING: Failed to delete duplicate room %s: %v", room.RoomNumber, err)
-			} else {
-				merged++
-			}
-		} else {
-			// No existing room with truncated number - just update the room_number
-			if err := DB.Model(&room).Update("room_number", newRoomNumber).Error; err != nil {
-				log.Printf("WARNING: Failed to update room %s: %v", room.RoomNumber, err)
-			} else {
-				fixed++
-			}
-		}
+	source := models.FeedSource{
+		TenantID:    tenant.ID,
+		BaseURL:     AppConfig.ICSBaseURL,
+		URLPattern:  "{base}/{zenturie}_{semester}.ics",
+		MaxSemester: 7,
+		Adapter:     "ics_url",
+		Encoding:    "latin1",
+		IsActive:    true,
+	}
+	if err := DB.Create(&source).Error; err != nil {
+		return fmt.Errorf("failed to create feed source: %w", err)
 	}
 
-	log.Printf("Room number migration complete: %d fixed, %d merged", fixed, merged)
+	log.Printf("Seeded feed source for tenant %s: %s", tenant.Slug, source.BaseURL)
 	return nil
 }
 
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/middleware"
+	"github.com/nora-nak/backend/models"
+)
+
+// EventChangeResponse represents one change of a timetable event
+type EventChangeResponse struct {
+	ID          uint       `json:"id"`
+	EventID     uint       `json:"event_id"`
+	UID         string     `json:"uid"`
+	Summary     string     `json:"summary"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	ChangeType  string     `json:"change_type"`
+	Field       *string    `json:"field,omitempty"`
+	OldValue    *string    `json:"old_value,omitempty"`
+	NewValue    *string    `json:"new_value,omitempty"`
+	Message     string     `json:"message"`
+	ImportRunID *uint      `json:"import_run_id,omitempty"`
+	ChangedAt   time.Time  `json:"changed_at"`
+}
+
+// changeFieldLabels maps change history fields to their German display names
+var changeFieldLabels = map[string]string{
+	"start_time":  "Beginn",
+	"end_time":    "Ende",
+	"all_day":     "Ganztägig",
+	"summary":     "Titel",
+	"room":        "Raum",
+	"course":      "Kurs",
+	"description": "Beschreibung",
+	"location":    "Ort",
+	"professor":   "Dozent",
+	"course_type": "Veranstaltungsart",
+	"course_code": "Modul",
+	"online_link": "Online-Link",
+	"notes":       "Hinweise",
+	"source_file": "Quelldatei",
+	"status":      "Status",
+	"zenturie":    "Zenturie",
+}
+
+// maxEventChanges limits the number of changes returned per request
+const maxEventChanges = 500
+
+// GetEventChanges returns the timetable changes of the user's zenturie
+// GET /v1/events/changes?since=2025-01-20 (or RFC3339 timestamp, default: last 7 days)
+// A since date starts at midnight in the tenant's time zone or tz (e.g. tz=Europe/Berlin).
+func GetEventChanges(c *fiber.Ctx) error {
+	user := middleware.GetCurrentUser(c)
+	tenantID := middleware.GetCurrentTenantID(c)
+
+	loc, err := requestLocation(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -7)
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			parsed, err = time.ParseInLocation("2006-01-02", sinceStr, loc)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"detail": "Ungültiges Datumsformat. Nutze YYYY-MM-DD oder RFC3339",
+			})
+		}
+		since = parsed
+	}
+
+	changes := make([]EventChangeResponse, 0)
+	if user.ZenturienID == nil {
+		return c.JSON(changes)
+	}
+
+	var records []models.TimetableChange
+	if err := config.DB.Preload("Timetable").
+		Where("tenant_id = ? AND zenturien_id = ? AND created_at >= ?", tenantID, *user.ZenturienID, since).
+		Order("created_at DESC, id DESC").
+		Limit(maxEventChanges).
+		Find(&records).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"detail": "Fehler beim Laden der Änderungen",
+		})
+	}
+
+	for _, record := range records {
+		change := EventChangeResponse{
+			ID:          record.ID,
+			EventID:     record.TimetableID,
+			UID:         record.UID,
+			ChangeType:  record.ChangeType,
+			Field:       record.Field,
+			OldValue:    record.OldValue,
+			NewValue:    record.NewValue,
+			ImportRunID: record.ImportRunID,
+			ChangedAt:   record.CreatedAt,
+		}
+		if record.Timetable != nil {
+			change.Summary = record.Timetable.Summary
+			start := record.Timetable.StartTime
+			change.StartTime = &start
+		}
+		change.Message = formatChangeMessage(&record)
+
+		changes = append(changes, change)
+	}
+
+	return c.JSON(changes)
+}
+
+// formatChangeMessage builds a German description, e.g. "Raum geändert von A101 auf B204"
+func formatChangeMessage(record *models.TimetableChange) string {
+	switch record.ChangeType {
+	case models.TimetableChangeCreated:
+		return "Neuer Termin"
+	case models.TimetableChangeCancelled:
+		return "Termin entfällt"
+	}
+
+	if record.Field == nil {
+		return "Termin geändert"
+	}
+
+	label, ok := changeFieldLabels[*record.Field]
+	if !ok {
+		label = *record.Field
+	}
+
+	oldValue := formatChangeValue(*record.Field, record.OldValue)
+	newValue := formatChangeValue(*record.Field, record.NewValue)
+
+	switch {
+	case oldValue == "":
+		return fmt.Sprintf("%s hinzugefügt: %s", label, newValue)
+	case newValue == "":
+		return fmt.Sprintf("%s entfernt (vorher %s)", label, oldValue)
+	default:
+		return fmt.Sprintf("%s geändert von %s auf %s", label, oldValue, newValue)
+	}
+}
+
+// formatChangeValue formats a stored change value for display (times in Europe/Berlin)
+func formatChangeValue(field string, value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	if field == "start_time" || field == "end_time" {
+		if t, err := time.Parse(time.RFC3339, *value); err == nil {
+			if berlinTZ, err := time.LoadLocation("Europe/Berlin"); err == nil {
+				t = t.In(berlinTZ)
+			}
+			return t.Format("02.01.2006 15:04")
+		}
+	}
+
+	return *value
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/middleware"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CourseSelectionRequest for enrolling in or dropping a course or event series
+type CourseSelectionRequest struct {
+	Action   string  `json:"action" validate:"required,oneof=enroll drop"`
+	Zenturie *string `json:"zenturie"` // Required for enroll, drop always uses the own zenturie
+	Course   *string `json:"course"`   // Module number, e.g. "I157"
+	Summary  *string `json:"summary"`  // Event title, for events without course (electives)
+}
+
+// CourseSelectionResponse represents a course selection of the user
+type CourseSelectionResponse struct {
+	ID        uint            `json:"id"`
+	Action    string          `json:"action"`
+	Zenturie  string          `json:"zenturie"`
+	Course    *CourseResponse `json:"course,omitempty"`
+	Summary   *string         `json:"summary,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// EventSeriesResponse represents a course or event series of a zenturie that can be selected
+type EventSeriesResponse struct {
+	Zenturie   string          `json:"zenturie"`
+	Title      string          `json:"title"`
+	Course     *CourseResponse `json:"course,omitempty"`
+	Summary    *string         `json:"summary,omitempty"` // Set for series without course
+	EventCount int64           `json:"event_count"`
+	Selection  *string         `json:"selection,omitempty"` // enroll or drop, if the user selected the series
+}
+
+// personalTimetables restricts a timetable query to the personal timetable of a user:
+// the events of the own zenturie without the dropped ones, plus the enrolled ones of other zenturien
+// Electives are imported once per zenturie with the same UID, each UID is kept once, preferring
+// the event of the own zenturie, then an active one.
+func personalTimetables(user *models.User) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var selections []models.CourseSelection
+		config.DB.Where("user_id = ?", user.ID).Find(&selections)
+
+		var clauses []string
+		var args []interface{}
+
+		if user.ZenturienID != nil {
+			own := "(zenturien_id = ?"
+			args = append(args, *user.ZenturienID)
+			for _, selection := range selections {
+				if selection.Action != models.CourseSelectionDrop || selection.ZenturienID != *user.ZenturienID {
+					continue
+				}
+				if selection.CourseID != nil {
+					// course_id is NULL for events without course, which a dropped course never hides
+					own += " AND course_id IS DISTINCT FROM ?"
+					args = append(args, *selection.CourseID)
+				} else if selection.Summary != nil {
+					own += " AND summary <> ?"
+					args = append(args, *selection.Summary)
+				}
+			}
+			clauses = append(clauses, own+")")
+		}
+
+		for _, selection := range selections {
+			if selection.Action != models.CourseSelectionEnroll {
+				continue
+			}
+			if selection.CourseID != nil {
+				clauses = append(clauses, "(zenturien_id = ? AND course_id = ?)")
+				args = append(args, selection.ZenturienID, *selection.CourseID)
+			} else if selection.Summary != nil {
+				clauses = append(clauses, "(zenturien_id = ? AND summary = ?)")
+				args = append(args, selection.ZenturienID, *selection.Summary)
+			}
+		}
+
+		if len(clauses) == 0 {
+			return db.Where("1 = 0")
+		}
+
+		var ownZenturieID uint
+		if user.ZenturienID != nil {
+			ownZenturieID = *user.ZenturienID
+		}
+		args = append(args, ownZenturieID, models.TimetableStatusActive)
+
+		return db.Where("id IN (SELECT DISTINCT ON (uid) id FROM timetables WHERE ("+strings.Join(clauses, " OR ")+")"+
+			" ORDER BY uid, zenturien_id = ? DESC, status = ? DESC, id)", args...)
+	}
+}
+
+// selectionMatches restricts a timetable query to the events a course selection refers to
+func selectionMatches(db *gorm.DB, zenturienID uint, courseID *uint, summary *string) *gorm.DB {
+	db = db.Where("zenturien_id = ?", zenturienID)
+	if courseID != nil {
+		return db.Where("course_id = ?", *courseID)
+	}
+	return db.Where("summary = ?", *summary)
+}
+
+// GetCourseSelections returns the enrolled and dropped courses of the user
+// GET /v1/course-selections
+func GetCourseSelections(c *fiber.Ctx) error {
+	user := middleware.GetCurrentUser(c)
+
+	var selections []models.CourseSelection
+	if err := config.DB.Preload("Zenturie").Preload("Course").
+		Where("user_id = ?", user.ID).Order("created_at").Find(&selections).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"detail": "Failed to fetch course selections",
+		})
+	}
+
+	response := make([]CourseSelectionResponse, len(selections))
+	for i := range selections {
+		response[i] = courseSelectionResponse(&selections[i])
+	}
+
+	return c.JSON(response)
+}
+
+// AddCourseSelection enrolls the user in a course or event series of another zenturie,
+// or drops one of the own zenturie
+// POST /v1/course-selections
+// Body: {"action": "enroll", "zenturie": "I24a", "course": "I157"} or {"action": "drop", "summary": "..."}
+func AddCourseSelection(c *fiber.Ctx) error {
+	user := middleware.GetCurrentUser(c)
+
+	var req CourseSelectionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": "Invalid request body",
+		})
+	}
+
+	if req.Action != models.CourseSelectionEnroll && req.Action != models.CourseSelectionDrop {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": "'action' muss 'enroll' oder 'drop' sein",
+		})
+	}
+
+	// Validate: Either course OR summary, not both
+	if req.Summary != nil && strings.TrimSpace(*req.Summary) == "" {
+		req.Summary = nil
+	}
+	if (req.Course == nil && req.Summary == nil) || (req.Course != nil && req.Summary != nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": "Entweder 'course' ODER 'summary' angeben, nicht beides",
+		})
+	}
+
+	// Find zenturie within tenant: drops refer to the own zenturie, enrollments to another one
+	tenantID := middleware.GetCurrentTenantID(c)
+	var zenturie models.Zenturie
+	if req.Action == models.CourseSelectionDrop {
+		if user.ZenturienID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"detail": "Du musst zuerst eine Zenturie auswählen",
+			})
+		}
+		if err := config.DB.First(&zenturie, *user.ZenturienID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"detail": "Zenturie nicht gefunden",
+			})
+		}
+	} else {
+		if req.Zenturie == nil || *req.Zenturie == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"detail": "'zenturie' ist zum Einschreiben erforderlich",
+			})
+		}
+		if err := config.DB.Where("tenant_id = ? AND name = ?", tenantID, *req.Zenturie).First(&zenturie).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"detail": "Zenturie '" + *req.Zenturie + "' nicht gefunden",
+			})
+		}
+		if user.ZenturienID != nil && zenturie.ID == *user.ZenturienID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"detail": "Die Termine deiner eigenen Zenturie sind bereits in deinem Stundenplan",
+			})
+		}
+	}
+
+	selection := models.CourseSelection{
+		UserID:      user.ID,
+		ZenturienID: zenturie.ID,
+		Action:      req.Action,
+		Summary:     req.Summary,
+	}
+
+	// If course is specified, find it within tenant
+	if req.Course != nil {
+		var course models.Course
+		if err := config.DB.Where("tenant_id = ? AND module_number = ?", tenantID, *req.Course).First(&course).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"detail": "Kurs nicht gefunden",
+			})
+		}
+		selection.CourseID = &course.ID
+	}
+
+	// The selection must match events of the zenturie
+	var eventCount int64
+	selectionMatches(config.DB.Model(&models.Timetable{}), zenturie.ID, selection.CourseID, selection.Summary).Count(&eventCount)
+	if eventCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"detail": "Keine Termine dieser Auswahl in Zenturie " + zenturie.Name + " gefunden",
+		})
+	}
+
+	// An existing selection violates unique_course_selection and is not inserted
+	result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&selection)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"detail": "Failed to save course selection",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": "Diese Auswahl ist bereits gespeichert",
+		})
+	}
+
+	config.DB.Preload("Zenturie").Preload("Course").First(&selection, selection.ID)
+	return c.JSON(courseSelectionResponse(&selection))
+}
+
+// DeleteCourseSelection removes a course selection, the events show as before again
+// DELETE /v1/course-selections?selection_id=123
+func DeleteCourseSelection(c *fiber.Ctx) error {
+	user := middleware.GetCurrentUser(c)
+
+	selectionID := c.QueryInt("selection_id", 0)
+	if selectionID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": "selection_id parameter required",
+		})
+	}
+
+	result := config.DB.Where("id = ? AND user_id = ?", selectionID, user.ID).Delete(&models.CourseSelection{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"detail": "Failed to delete course selection",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"detail": "Auswahl nicht gefunden oder du hast keine Berechtigung",
+		})
+	}
+
+	return c.JSON(MessageResponse{
+		Message: "Auswahl erfolgreich entfernt",
+	})
+}
+
+// GetEventSeries returns the courses and event series of a zenturie with the user's selection
+// GET /v1/course-selections/series?zenturie=I24a (default: own zenturie)
+// Events with a course are grouped by course, all others by title.
+func GetEventSeries(c *fiber.Ctx) error {
+	user := middleware.GetCurrentUser(c)
+	tenantID := middleware.GetCurrentTenantID(c)
+
+	var zenturie models.Zenturie
+	if name := c.Query("zenturie"); name != "" {
+		if err := config.DB.Where("tenant_id = ? AND name = ?", tenantID, name).First(&zenturie).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"detail": "Zenturie '" + name + "' nicht gefunden",
+			})
+		}
+	} else {
+		if user.ZenturienID == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"detail": "zenturie parameter required",
+			})
+		}
+		if err := config.DB.First(&zenturie, *user.ZenturienID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"detail": "Zenturie nicht gefunden",
+			})
+		}
+	}
+
+	var rows []struct {
+		CourseID   *uint
+		Summary    *string
+		EventCount int64
+	}
+	if err := config.DB.Model(&models.Timetable{}).
+		Select("course_id, CASE WHEN course_id IS NULL THEN summary END AS summary, COUNT(*) AS event_count").
+		Where("tenant_id = ? AND zenturien_id = ? AND status = ?", tenantID, zenturie.ID, models.TimetableStatusActive).
+		Group("course_id, CASE WHEN course_id IS NULL THEN summary END").
+		Scan(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"detail": "Failed to fetch event series",
+		})
+	}
+
+	var courseIDs []uint
+	for _, row := range rows {
+		if row.CourseID != nil {
+			courseIDs = append(courseIDs, *row.CourseID)
+		}
+	}
+	courses := make(map[uint]models.Course)
+	if len(courseIDs) > 0 {
+		var found []models.Course
+		config.DB.Where("id IN ?", courseIDs).Find(&found)
+		for _, course := range found {
+			courses[course.ID] = course
+		}
+	}
+
+	var selections []models.CourseSelection
+	config.DB.Where("user_id = ? AND zenturien_id = ?", user.ID, zenturie.ID).Find(&selections)
+
+	response := make([]EventSeriesResponse, 0, len(rows))
+	for _, row := range rows {
+		series := EventSeriesResponse{
+			Zenturie:   zenturie.Name,
+			Summary:    row.Summary,
+			EventCount: row.EventCount,
+		}
+		if row.CourseID != nil {
+			course := courses[*row.CourseID]
+			series.Title = course.Name
+			series.Course = &CourseResponse{ID: course.ID, ModuleNumber: course.ModuleNumber, Name: course.Name}
+		} else if row.Summary != nil {
+			series.Title = *row.Summary
+		}
+
+		for _, selection := range selections {
+			if (row.CourseID != nil && selection.CourseID != nil && *selection.CourseID == *row.CourseID) ||
+				(row.Summary != nil && selection.Summary != nil && *selection.Summary == *row.Summary) {
+				action := selection.Action
+				series.Selection = &action
+			}
+		}
+
+		response = append(response, series)
+	}
+
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].Title < response[j].Title
+	})
+
+	return c.JSON(response)
+}
+
+// courseSelectionResponse converts a course selection with preloaded zenturie and course
+func courseSelectionResponse(selection *models.CourseSelection) CourseSelectionResponse {
+	response := CourseSelectionResponse{
+		ID:        selection.ID,
+		Action:    selection.Action,
+		Summary:   selection.Summary,
+		CreatedAt: selection.CreatedAt,
+	}
+	if selection.Zenturie != nil {
+		response.Zenturie = selection.Zenturie.Name
+	}
+	if selection.Course != nil {
+		response.Course = &CourseResponse{
+			ID:           selection.Course.ID,
+			ModuleNumber: selection.Course.ModuleNumber,
+			Name:         selection.Course.Name,
+		}
+	}
+	return response
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+)
+
+// Event types of EventResponse
+const (
+	EventTypeTimetable  = "timetable"
+	EventTypeCustomHour = "custom_hour"
+	EventTypeExam       = "exam"
+)
+
+// maxEventsLimit caps the page size of event lists
+const maxEventsLimit = 500
+
+// nextCursorHeader carries the cursor of the next page of a paginated event list
+const nextCursorHeader = "X-Next-Cursor"
+
+// eventTypeOrder orders events with the same start time
+var eventTypeOrder = map[string]int{
+	EventTypeTimetable:  0,
+	EventTypeCustomHour: 1,
+	EventTypeExam:       2,
+}
+
+// eventIncludeNames maps the values of the include query parameter to event types
+var eventIncludeNames = map[string]string{
+	"timetable":   EventTypeTimetable,
+	"custom":      EventTypeCustomHour,
+	"custom_hour": EventTypeCustomHour,
+	"exam":        EventTypeExam,
+}
+
+// parseEventInclude parses the include query parameter (e.g. "timetable,custom,exam") into the requested event types
+// Without include, timetable events and custom hours are returned
+func parseEventInclude(value string) (map[string]bool, error) {
+	if strings.TrimSpace(value) == "" {
+		return map[string]bool{EventTypeTimetable: true, EventTypeCustomHour: true}, nil
+	}
+
+	include := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		eventType, ok := eventIncludeNames[name]
+		if !ok {
+			return nil, fmt.Errorf("Unbekannter Event-Typ '%s'. Erlaubt: timetable, custom, exam", name)
+		}
+		include[eventType] = true
+	}
+	return include, nil
+}
+
+// eventCursor is the position of the last event of a page
+type eventCursor struct {
+	StartTime time.Time
+	EventType string
+	ID        uint
+}
+
+// encode returns the opaque cursor string
+func (cur eventCursor) encode() string {
+	raw := fmt.Sprintf("%d:%s:%d", cur.StartTime.UnixNano(), cur.EventType, cur.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeEventCursor parses a cursor returned in the X-Next-Cursor header
+func decodeEventCursor(value string) (*eventCursor, error) {
+	invalid := errors.New("Ungültiger Cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	if _, ok := eventTypeOrder[parts[1]]; !ok {
+		return nil, invalid
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &eventCursor{StartTime: time.Unix(0, nanos).UTC(), EventType: parts[1], ID: uint(id)}, nil
+}
+
+// eventPage is the requested page of an event list, limit 0 returns all events
+type eventPage struct {
+	limit int
+	after *eventCursor
+}
+
+// parseEventPage parses the limit and cursor query parameters
+func parseEventPage(c *fiber.Ctx) (eventPage, error) {
+	var page eventPage
+
+	page.limit = c.QueryInt("limit", 0)
+	if page.limit < 0 {
+		return page, errors.New("limit darf nicht negativ sein")
+	}
+	if page.limit > maxEventsLimit {
+		page.limit = maxEventsLimit
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := decodeEventCursor(value)
+		if err != nil {
+			return page, err
+		}
+		page.after = cursor
+	}
+	return page, nil
+}
+
+// scope restricts the query of one event type to the events after the cursor, sorted like the event list
+// One event more than the limit is loaded, so the merged list knows whether another page follows.
+func (p eventPage) scope(eventType string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.after != nil {
+			after := p.after
+			switch order := eventTypeOrder[eventType] - eventTypeOrder[after.EventType]; {
+			case order < 0:
+				db = db.Where("start_time > ?", after.StartTime)
+			case order == 0:
+				db = db.Where("start_time > ? OR (start_time = ? AND id > ?)", after.StartTime, after.StartTime, after.ID)
+			default:
+				db = db.Where("start_time >= ?", after.StartTime)
+			}
+		}
+		db = db.Order("start_time, id")
+		if p.limit > 0 {
+			db = db.Limit(p.limit + 1)
+		}
+		return db
+	}
+}
+
+// apply sorts the events and cuts them to the page
+// Sets the X-Next-Cursor header if more events follow.
+func (p eventPage) apply(c *fiber.Ctx, events []EventResponse) []EventResponse {
+	sortEvents(events)
+	if p.limit == 0 || len(events) <= p.limit {
+		return events
+	}
+
+	events = events[:p.limit]
+	last := events[len(events)-1]
+	c.Set(nextCursorHeader, eventCursor{StartTime: last.StartTime, EventType: last.EventType, ID: last.ID}.encode())
+	return events
+}
+
+// sortEvents sorts events by start time, then by type and id
+func sortEvents(events []EventResponse) {
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		if a.EventType != b.EventType {
+			return eventTypeOrder[a.EventType] < eventTypeOrder[b.EventType]
+		}
+		return a.ID < b.ID
+	})
+}
+
+// timetableEvent converts a timetable event into its EventResponse
+// Requires the rooms loaded by withTimetableRooms
+func timetableEvent(tt *models.Timetable) EventResponse {
+	event := EventResponse{
+		EventType:   EventTypeTimetable,
+		ID:          tt.ID,
+		Title:       tt.Summary,
+		StartTime:   tt.StartTime.UTC(),
+		EndTime:     tt.EndTime.UTC(),
+		Description: tt.Description,
+		UID:         tt.UID,
+		Professor:   tt.Professor,
+		CourseCode:  tt.CourseCode,
+		OnlineLink:  tt.OnlineLink,
+		Notes:       tt.Notes,
+		Rooms:       timetableRoomNumbers(tt),
+		Color:       tt.Color,
+		BorderColor: tt.BorderColor,
+		Status:      tt.Status,
+		CancelledAt: tt.CancelledAt,
+		IsAllDay:    tt.IsAllDay,
+	}
+	if len(event.Rooms) > 0 {
+		location := strings.Join(event.Rooms, ", ")
+		event.Room, event.Location = &event.Rooms[0], &location
+	}
+	if tt.Zenturie != nil {
+		event.Zenturie = tt.Zenturie.Name
+	}
+	return event
+}
+
+// customHourEvent converts a custom hour into its EventResponse, requires the preloaded Room
+func customHourEvent(ch *models.CustomHour) EventResponse {
+	var roomStr *string
+	if ch.Room != nil {
+		roomStr = &ch.Room.RoomNumber
+	} else if ch.CustomLocation != nil {
+		roomStr = ch.CustomLocation
+	}
+
+	return EventResponse{
+		EventType:      EventTypeCustomHour,
+		ID:             ch.ID,
+		Title:          ch.Title,
+		StartTime:      ch.StartTime.UTC(),
+		EndTime:        ch.EndTime.UTC(),
+		Location:       roomStr,
+		Room:           roomStr,
+		Description:    ch.Description,
+		CustomLocation: ch.CustomLocation,
+	}
+}
+
+// examEvent converts an exam into its EventResponse, requires the preloaded Course and Room
+// The end time is derived from the duration
+func examEvent(exam *models.Exam) EventResponse {
+	var roomStr *string
+	if exam.Room != nil {
+		roomStr = &exam.Room.RoomNumber
+	}
+
+	event := EventResponse{
+		EventType:  EventTypeExam,
+		ID:         exam.ID,
+		StartTime:  exam.StartTime.UTC(),
+		EndTime:    exam.StartTime.Add(time.Duration(exam.Duration) * time.Minute).UTC(),
+		Location:   roomStr,
+		Room:       roomStr,
+		Duration:   exam.Duration,
+		IsVerified: exam.IsVerified,
+	}
+	if exam.Course != nil {
+		event.Title = exam.Course.Name
+		event.ModuleNumber = exam.Course.ModuleNumber
+	}
+	return event
+}
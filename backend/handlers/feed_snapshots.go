@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
+	"gorm.io/gorm"
+)
+
+// GetTenantFeedSnapshots lists the archived feed files of a tenant, newest first (ADMIN ONLY)
+// GET /v1/admin/tenants/:id/feed-snapshots?zenturie=I24c&source_file=I24c_3.ics&before=2025-01-20T08:00:00Z&limit=50&offset=0
+// With before, the first snapshot per file is the content the feed served at that time.
+func GetTenantFeedSnapshots(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > maxImportRunsLimit {
+		limit = maxImportRunsLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := config.DB.Model(&models.FeedSnapshot{}).Where("tenant_id = ?", tenant.ID)
+	if zenturie := c.Query("zenturie"); zenturie != "" {
+		query = query.Where("zenturie = ?", zenturie)
+	}
+	if sourceFile := c.Query("source_file"); sourceFile != "" {
+		query = query.Where("source_file = ?", sourceFile)
+	}
+	if before := c.Query("before"); before != "" {
+		t, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "before must be an RFC 3339 timestamp",
+			})
+		}
+		query = query.Where("fetched_at <= ?", t)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count feed snapshots",
+		})
+	}
+
+	// Only the sizes of the content, not the payload itself
+	var snapshots []models.FeedSnapshot
+	err := query.
+		Preload("Content", func(db *gorm.DB) *gorm.DB {
+			return db.Select("content_hash", "size", "compressed_size")
+		}).
+		Order("fetched_at DESC, id DESC").Limit(limit).Offset(offset).
+		Find(&snapshots).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch feed snapshots",
+		})
+	}
+
+	response := make([]fiber.Map, len(snapshots))
+	for i, snapshot := range snapshots {
+		response[i] = feedSnapshotMap(&snapshot)
+	}
+
+	return c.JSON(fiber.Map{
+		"snapshots": response,
+		"count":     len(response),
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+	})
+}
+
+// GetFeedSnapshot returns an archived feed file with its content (ADMIN ONLY)
+// GET /v1/admin/feed-snapshots/:id
+// GET /v1/admin/feed-snapshots/:id?download=true returns the ICS file itself
+func GetFeedSnapshot(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid snapshot ID",
+		})
+	}
+
+	snapshot, content, err := services.LoadFeedSnapshot(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrSnapshotNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Feed snapshot not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load feed snapshot",
+		})
+	}
+
+	if c.QueryBool("download") {
+		c.Set("Content-Type", "text/calendar; charset=utf-8")
+		c.Set("Content-Disposition", "attachment; filename=\""+snapshot.SourceFile+"\"")
+		return c.SendString(content)
+	}
+
+	response := feedSnapshotMap(snapshot)
+	response["content"] = content
+	return c.JSON(response)
+}
+
+// ReplayFeedSnapshot imports an archived feed file again (ADMIN ONLY)
+// POST /v1/admin/feed-snapshots/:id/replay
+// Body (optional): {"dry_run": true}
+// A dry run returns the created, updated and cancelled events without writing them.
+func ReplayFeedSnapshot(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid snapshot ID",
+		})
+	}
+
+	var req struct {
+		DryRun bool `json:"dry_run"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	result, err := services.ReplayFeedSnapshot(uint(id), req.DryRun)
+	switch {
+	case errors.Is(err, services.ErrSnapshotNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Feed snapshot not found",
+		})
+	case errors.Is(err, services.ErrImportInProgress):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "An import is already running, try again later",
+		})
+	case err != nil && result == nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to replay feed snapshot",
+		})
+	}
+
+	response := fiber.Map{
+		"snapshot_id":   id,
+		"dry_run":       req.DryRun,
+		"status":        services.ImportRunStatus(nil, err),
+		"import_run_id": result.RunID,
+	}
+	if stats := result.Stats; stats != nil {
+		response["status"] = services.ImportRunStatus(stats, err)
+		response["statistics"] = fiber.Map{
+			"events_created":   stats.EventsCreated,
+			"events_updated":   stats.EventsUpdated,
+			"events_unchanged": stats.EventsUnchanged,
+			"events_cancelled": stats.EventsCancelled,
+			"errors":           stats.Errors,
+		}
+		if stats.Preview != nil {
+			response["preview"] = stats.Preview
+		}
+	}
+
+	if err != nil {
+		response["status"] = models.ImportRunStatusFailed
+		response["error"] = err.Error()
+		return c.Status(fiber.StatusInternalServerError).JSON(response)
+	}
+
+	return c.JSON(response)
+}
+
+// feedSnapshotMap builds the response of a feed snapshot including its content sizes
+func feedSnapshotMap(snapshot *models.FeedSnapshot) fiber.Map {
+	response := fiber.Map{
+		"id":            snapshot.ID,
+		"tenant_id":     snapshot.TenantID,
+		"zenturie":      snapshot.Zenturie,
+		"source_file":   snapshot.SourceFile,
+		"url":           snapshot.URL,
+		"content_hash":  snapshot.ContentHash,
+		"import_run_id": snapshot.ImportRunID,
+		"fetched_at":    snapshot.FetchedAt,
+	}
+	if snapshot.Content != nil {
+		response["size"] = snapshot.Content.Size
+		response["compressed_size"] = snapshot.Content.CompressedSize
+	}
+	return response
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/middleware"
 	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
 )
 
 // GetICSSubscription generates ICS calendar file for subscription
@@ -38,30 +40,35 @@ func GetICSSubscription(c *fiber.Ctx) error {
 
 	var events []string
 
-	// Add timetable events
-	if user.ZenturienID != nil {
-		var timetables []models.Timetable
-		config.DB.Preload("Room").Where("zenturien_id = ? AND start_time >= ? AND start_time <= ?",
-			*user.ZenturienID, startDate, endDate).Find(&timetables)
-
-		for _, tt := range timetables {
-			location := ""
-			if tt.Room != nil {
-				location = tt.Room.RoomNumber
-			} else if tt.Location != nil {
-				location = *tt.Location
-			}
-
-			event := generateICSEvent(
-				tt.UID,
-				tt.Summary,
-				strings.Replace(stringValue(tt.Description), "\\n", "\n", -1),
-				location,
-				tt.StartTime,
-				tt.EndTime,
-			)
-			events = append(events, event)
+	// All-day events are exported as dates in the tenant's time zone
+	loc := services.TenantLocation(middleware.GetCurrentTenant(c))
+
+	// Add timetable events of the user's zenturie and course selection
+	var timetables []models.Timetable
+	config.DB.Scopes(withTimetableRooms, personalTimetables(&user)).Where("start_time >= ? AND start_time <= ?",
+		startDate, endDate).Find(&timetables)
+
+	// personalTimetables returns each UID once, calendar apps expect unique UIDs
+	for _, tt := range timetables {
+		location := ""
+		if rooms := timetableRoomNumbers(&tt); len(rooms) > 0 {
+			location = strings.Join(rooms, ", ")
+		} else if tt.Location != nil {
+			location = *tt.Location
 		}
+
+		event := generateICSEvent(
+			tt.UID,
+			tt.Summary,
+			strings.Replace(stringValue(tt.Description), "\\n", "\n", -1),
+			location,
+			tt.StartTime,
+			tt.EndTime,
+			tt.Status == models.TimetableStatusCancelled,
+			tt.IsAllDay,
+			loc,
+		)
+		events = append(events, event)
 	}
 
 	// Add custom hours
@@ -85,6 +92,9 @@ func GetICSSubscription(c *fiber.Ctx) error {
 			location,
 			ch.StartTime,
 			ch.EndTime,
+			false,
+			false,
+			nil,
 		)
 		events = append(events, event)
 	}
@@ -113,6 +123,9 @@ func GetICSSubscription(c *fiber.Ctx) error {
 			location,
 			exam.StartTime,
 			endTime,
+			false,
+			false,
+			nil,
 		)
 		events = append(events, event)
 	}
@@ -126,12 +139,21 @@ func GetICSSubscription(c *fiber.Ctx) error {
 }
 
 // generateICSEvent creates an ICS VEVENT string
-func generateICSEvent(uid, summary, description, location string, startTime, endTime time.Time) string {
+// Cancelled events are kept in the feed with STATUS:CANCELLED so calendar apps can show them as such
+// All-day events are written as VALUE=DATE in loc
+func generateICSEvent(uid, summary, description, location string, startTime, endTime time.Time, cancelled, allDay bool, loc *time.Location) string {
 	// Format times in ICS format (YYYYMMDDTHHMMSSZ)
 	formatICSTime := func(t time.Time) string {
 		return t.UTC().Format("20060102T150405Z")
 	}
 
+	dtStart := "DTSTART:" + formatICSTime(startTime)
+	dtEnd := "DTEND:" + formatICSTime(endTime)
+	if allDay && loc != nil {
+		dtStart = "DTSTART;VALUE=DATE:" + startTime.In(loc).Format("20060102")
+		dtEnd = "DTEND;VALUE=DATE:" + endTime.In(loc).Format("20060102")
+	}
+
 	// Escape special characters in ICS
 	escapeICS := func(s string) string {
 		s = strings.ReplaceAll(s, "\\", "\\\\")
@@ -141,16 +163,20 @@ func generateICSEvent(uid, summary, description, location string, startTime, end
 		return s
 	}
 
+	if cancelled {
+		summary = "Entfällt: " + summary
+	}
+
 	event := fmt.Sprintf(`BEGIN:VEVENT
 UID:%s
 DTSTAMP:%s
-DTSTART:%s
-DTEND:%s
+%s
+%s
 SUMMARY:%s`,
 		uid,
 		formatICSTime(time.Now()),
-		formatICSTime(startTime),
-		formatICSTime(endTime),
+		dtStart,
+		dtEnd,
 		escapeICS(summary),
 	)
 
@@ -162,6 +188,10 @@ SUMMARY:%s`,
 		event += fmt.Sprintf("\nLOCATION:%s", escapeICS(location))
 	}
 
+	if cancelled {
+		event += "\nSTATUS:CANCELLED"
+	}
+
 	event += "\nEND:VEVENT"
 
 	return event
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/middleware"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
+)
+
+// GetImportQuarantines lists files held back by the import guards, newest first (ADMIN ONLY)
+// GET /v1/admin/import-quarantines?tenant_id=1&status=pending&limit=50&offset=0
+func GetImportQuarantines(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > maxImportRunsLimit {
+		limit = maxImportRunsLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := config.DB.Model(&models.ImportQuarantine{})
+	if tenantID := c.QueryInt("tenant_id", 0); tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count quarantined files",
+		})
+	}
+
+	var quarantines []models.ImportQuarantine
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&quarantines).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch quarantined files",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"quarantines": quarantines,
+		"count":       len(quarantines),
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetImportQuarantine returns one quarantined file with its held back events (ADMIN ONLY)
+// GET /v1/admin/import-quarantines/:id
+func GetImportQuarantine(c *fiber.Ctx) error {
+	var quarantine models.ImportQuarantine
+	if err := config.DB.First(&quarantine, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Quarantined file not found",
+		})
+	}
+
+	var events []services.TimetableEvent
+	if err := json.Unmarshal([]byte(quarantine.Events), &events); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to decode quarantined events",
+		})
+	}
+
+	previews := make([]fiber.Map, len(events))
+	for i, event := range events {
+		previews[i] = fiber.Map{
+			"uid":        event.UID,
+			"summary":    event.Summary,
+			"start_time": event.StartTime,
+			"end_time":   event.EndTime,
+			"location":   event.Location,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"quarantine": quarantine,
+		"reasons":    strings.Split(quarantine.Reasons, "\n"),
+		"events":     previews,
+	})
+}
+
+// ApproveImportQuarantine imports a quarantined file as it is (ADMIN ONLY)
+// POST /v1/admin/import-quarantines/:id/approve
+func ApproveImportQuarantine(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid quarantine ID",
+		})
+	}
+
+	result, err := services.ApproveQuarantine(uint(id), reviewerID(c))
+	if err != nil {
+		return quarantineReviewError(c, err, "Failed to import quarantined file")
+	}
+
+	response := fiber.Map{
+		"message":       "Quarantined file imported",
+		"import_run_id": result.RunID,
+	}
+	if stats := result.Stats; stats != nil {
+		response["statistics"] = fiber.Map{
+			"events_created":   stats.EventsCreated,
+			"events_updated":   stats.EventsUpdated,
+			"events_unchanged": stats.EventsUnchanged,
+			"events_cancelled": stats.EventsCancelled,
+			"errors":           stats.Errors,
+		}
+	}
+
+	return c.JSON(response)
+}
+
+// RejectImportQuarantine discards a quarantined file (ADMIN ONLY)
+// POST /v1/admin/import-quarantines/:id/reject
+// The same file content is not quarantined again, a changed file is checked anew.
+func RejectImportQuarantine(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid quarantine ID",
+		})
+	}
+
+	if err := services.RejectQuarantine(uint(id), reviewerID(c)); err != nil {
+		return quarantineReviewError(c, err, "Failed to reject quarantined file")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Quarantined file rejected",
+	})
+}
+
+// reviewerID returns the ID of the admin reviewing a quarantined file
+func reviewerID(c *fiber.Ctx) *uint {
+	if user := middleware.GetCurrentUser(c); user != nil {
+		return &user.ID
+	}
+	return nil
+}
+
+// quarantineReviewError maps the errors of approving or rejecting a quarantined file
+func quarantineReviewError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, services.ErrQuarantineNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Quarantined file not found",
+		})
+	case errors.Is(err, services.ErrQuarantineNotPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Quarantined file was already reviewed",
+		})
+	case errors.Is(err, services.ErrImportInProgress):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "An import is already running, try again later",
+		})
+	case errors.Is(err, services.ErrQuarantineImportFailed):
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Quarantined file could not be imported and is still pending",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
+	"gorm.io/gorm"
+)
+
+// maxImportRunsLimit caps the page size of GetImportRuns
+const maxImportRunsLimit = 200
+
+// GetImportRuns lists import runs, newest first (ADMIN ONLY)
+// GET /v1/admin/import-runs?tenant_id=1&status=failed&limit=50&offset=0
+func GetImportRuns(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > maxImportRunsLimit {
+		limit = maxImportRunsLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := config.DB.Model(&models.ImportRun{})
+	if tenantID := c.QueryInt("tenant_id", 0); tenantID > 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count import runs",
+		})
+	}
+
+	var runs []models.ImportRun
+	if err := query.Order("started_at DESC, id DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch import runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"import_runs": runs,
+		"count":       len(runs),
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetImportRun returns one import run with its per-zenturie and per-file results (ADMIN ONLY)
+// GET /v1/admin/import-runs/:id
+func GetImportRun(c *fiber.Ctx) error {
+	runID := c.Params("id")
+
+	var run models.ImportRun
+	err := config.DB.
+		Preload("Zenturien", func(db *gorm.DB) *gorm.DB { return db.Order("zenturie ASC") }).
+		Preload("Files", func(db *gorm.DB) *gorm.DB { return db.Order("zenturie ASC, source_file ASC") }).
+		First(&run, runID).Error
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Import run not found",
+		})
+	}
+
+	return c.JSON(run)
+}
+
+// TriggerTenantImport starts an import of a tenant on demand (ADMIN ONLY)
+// POST /v1/admin/tenants/:id/import
+// Body (optional): {"zenturie": "I24c", "dry_run": true}
+// A dry run returns the created, updated and cancelled events without writing them.
+func TriggerTenantImport(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var req struct {
+		Zenturie string `json:"zenturie"`
+		DryRun   bool   `json:"dry_run"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	result, err := services.RunManualImport(&tenant, req.Zenturie, req.DryRun)
+	switch {
+	case errors.Is(err, services.ErrImportInProgress):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "An import is already running, try again later",
+		})
+	case errors.Is(err, services.ErrNoActiveFeedSource):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Tenant has no active feed source",
+		})
+	case errors.Is(err, services.ErrZenturieNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Zenturie not found",
+		})
+	}
+
+	response := fiber.Map{
+		"tenant":   tenant.Slug,
+		"zenturie": req.Zenturie,
+		"dry_run":  req.DryRun,
+		"status":   services.ImportRunStatus(nil, err),
+	}
+	if result != nil {
+		response["import_run_id"] = result.RunID
+		response["files"] = result.Files
+		if stats := result.Stats; stats != nil {
+			response["status"] = services.ImportRunStatus(stats, err)
+			response["statistics"] = fiber.Map{
+				"files_downloaded":        stats.FilesDownloaded,
+				"files_skipped_unchanged": stats.FilesSkippedUnchanged,
+				"files_failed":            stats.FilesFailed,
+				"events_created":          stats.EventsCreated,
+				"events_updated":          stats.EventsUpdated,
+				"events_unchanged":        stats.EventsUnchanged,
+				"events_cancelled":        stats.EventsCancelled,
+				"files_quarantined":       len(stats.Quarantined),
+				"errors":                  stats.Errors,
+			}
+			if stats.Preview != nil {
+				response["preview"] = stats.Preview
+			}
+		}
+	}
+
+	if err != nil {
+		response["status"] = models.ImportRunStatusFailed
+		response["error"] = err.Error()
+		return c.Status(fiber.StatusBadGateway).JSON(response)
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/middleware"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
+)
+
+// LecturerResponse represents lecturer information
+type LecturerResponse struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	EventCount int64  `json:"event_count"`
+}
+
+// lecturerWithCount is a lecturer row with the number of its active events
+type lecturerWithCount struct {
+	models.Lecturer
+	EventCount int64
+}
+
+// findLecturersWithCounts loads the lecturers of a tenant with their number of active events
+func findLecturersWithCounts(tenantID uint, includeMerged bool) ([]lecturerWithCount, error) {
+	query := config.DB.Model(&models.Lecturer{}).
+		Select("lecturers.*, (SELECT COUNT(*) FROM timetables WHERE timetables.lecturer_id = lecturers.id AND timetables.status = ?) AS event_count",
+			models.TimetableStatusActive).
+		Where("lecturers.tenant_id = ?", tenantID)
+	if !includeMerged {
+		query = query.Where("lecturers.merged_into_id IS NULL")
+	}
+
+	var lecturers []lecturerWithCount
+	err := query.Order("lecturers.name").Scan(&lecturers).Error
+	return lecturers, err
+}
+
+// GetLecturers returns all lecturers of the tenant
+// GET /v1/lecturers
+func GetLecturers(c *fiber.Ctx) error {
+	tenantID := middleware.GetCurrentTenantID(c)
+
+	lecturers, err := findLecturersWithCounts(tenantID, false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"detail": "Failed to fetch lecturers",
+		})
+	}
+
+	response := make([]LecturerResponse, len(lecturers))
+	for i, lecturer := range lecturers {
+		response[i] = LecturerResponse{
+			ID:         lecturer.ID,
+			Name:       lecturer.Name,
+			EventCount: lecturer.EventCount,
+		}
+	}
+
+	return c.JSON(response)
+}
+
+// GetLecturerTimetable returns the events of a lecturer across all zenturien
+// GET /v1/lecturers/:id/timetable?date=2025-01-20&end=2025-01-26&tz=Europe/Berlin
+func GetLecturerTimetable(c *fiber.Ctx) error {
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": "Date parameter required (YYYY-MM-DD)",
+		})
+	}
+
+	loc, err := requestLocation(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
+
+	startOfDay, endOfDay, err := parseDateRange(dateStr, c.Query("end"), loc)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
+
+	// Find lecturer within tenant, merged lecturers show the lecturer they were merged into
+	tenantID := middleware.GetCurrentTenantID(c)
+	var lecturer models.Lecturer
+	if err := config.DB.Where("tenant_id = ?", tenantID).First(&lecturer, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"detail": "Dozent nicht gefunden",
+		})
+	}
+	if lecturer.MergedIntoID != nil {
+		if err := config.DB.First(&lecturer, *lecturer.MergedIntoID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"detail": "Dozent nicht gefunden",
+			})
+		}
+	}
+
+	// The same lecture is often imported once per zenturie, it is returned once with all its zenturien
+	var timetables []models.Timetable
+	config.DB.Scopes(withTimetableRooms).Preload("Zenturie").
+		Where("tenant_id = ? AND lecturer_id = ? AND start_time >= ? AND start_time <= ?",
+			tenantID, lecturer.ID, startOfDay, endOfDay).
+		Order("zenturien_id ASC").
+		Find(&timetables)
+
+	events := make([]EventResponse, 0, len(timetables))
+	seen := make(map[string]int) // UID + start time -> index in events
+	for i := range timetables {
+		event := timetableEvent(&timetables[i])
+		key := event.UID + "|" + event.StartTime.Format(time.RFC3339)
+		if idx, ok := seen[key]; ok {
+			if event.Zenturie != "" {
+				events[idx].Zenturien = append(events[idx].Zenturien, event.Zenturie)
+			}
+			continue
+		}
+		if event.Zenturie != "" {
+			event.Zenturien = []string{event.Zenturie}
+		}
+		seen[key] = len(events)
+		events = append(events, event)
+	}
+	sortEvents(events)
+
+	return c.JSON(fiber.Map{
+		"lecturer": LecturerResponse{
+			ID:   lecturer.ID,
+			Name: lecturer.Name,
+		},
+		"events":    events,
+		"time_zone": loc.String(),
+	})
+}
+
+// GetTenantLecturers returns all lecturers of a tenant including merged ones (ADMIN ONLY)
+// GET /v1/admin/tenants/:id/lecturers
+func GetTenantLecturers(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	lecturers, err := findLecturersWithCounts(tenant.ID, true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch lecturers",
+		})
+	}
+
+	response := make([]fiber.Map, len(lecturers))
+	for i, lecturer := range lecturers {
+		response[i] = fiber.Map{
+			"id":              lecturer.ID,
+			"name":            lecturer.Name,
+			"normalized_name": lecturer.NormalizedName,
+			"merged_into_id":  lecturer.MergedIntoID,
+			"event_count":     lecturer.EventCount,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"lecturers": response,
+		"count":     len(response),
+	})
+}
+
+// MergeTenantLecturers merges duplicate lecturers of a tenant into one (ADMIN ONLY)
+// POST /v1/admin/tenants/:id/lecturers/merge
+// Body: {"target_id": 1, "source_ids": [2, 3]}
+// Events of the sources are moved to the target, future imports assign their names to the target.
+func MergeTenantLecturers(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var req struct {
+		TargetID  uint   `json:"target_id"`
+		SourceIDs []uint `json:"source_ids"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	err := services.MergeLecturers(tenant.ID, req.TargetID, req.SourceIDs)
+	switch {
+	case errors.Is(err, services.ErrLecturerNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Lecturer not found",
+		})
+	case errors.Is(err, services.ErrInvalidMerge):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to merge lecturers",
+		})
+	}
+
+	var target models.Lecturer
+	config.DB.First(&target, req.TargetID)
+
+	return c.JSON(fiber.Map{
+		"message":  "Lecturers merged successfully",
+		"lecturer": target,
+	})
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
+)
+
+// GetTenantRetention returns the retention periods of a tenant in days (ADMIN ONLY)
+// GET /v1/admin/tenants/:id/retention
+// Tenants without a stored policy get the defaults, 0 keeps the data forever.
+func GetTenantRetention(c *fiber.Ctx) error {
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	policy, err := services.GetRetentionPolicy(tenant.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch retention policy",
+		})
+	}
+
+	return c.JSON(policy)
+}
+
+// UpdateTenantRetention changes the retention periods of a tenant (ADMIN ONLY)
+// PUT /v1/admin/tenants/:id/retention
+// Body: {"timetable_days": 365, "friend_request_days": 30, "custom_hour_days": 180}
+func UpdateTenantRetention(c *fiber.Ctx) error {
+	var req struct {
+		TimetableDays     *int `json:"timetable_days"`
+		FriendRequestDays *int `json:"friend_request_days"`
+		CustomHourDays    *int `json:"custom_hour_days"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	policy, err := services.GetRetentionPolicy(tenant.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch retention policy",
+		})
+	}
+
+	fields := []struct {
+		name   string
+		value  *int
+		target *int
+	}{
+		{"timetable_days", req.TimetableDays, &policy.TimetableDays},
+		{"friend_request_days", req.FriendRequestDays, &policy.FriendRequestDays},
+		{"custom_hour_days", req.CustomHourDays, &policy.CustomHourDays},
+	}
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		if *field.value < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": field.name + " must not be negative",
+			})
+		}
+		*field.target = *field.value
+	}
+
+	if err := services.SaveRetentionPolicy(policy); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save retention policy",
+		})
+	}
+
+	return c.JSON(policy)
+}
+
+// RunRetention deletes the data of all tenants past their retention periods and the old log
+// files of this instance (ADMIN ONLY)
+// POST /v1/admin/retention/run
+// Body (optional): {"dry_run": true}
+// A dry run reports what would be deleted without deleting it.
+func RunRetention(c *fiber.Ctx) error {
+	var req struct {
+		DryRun bool `json:"dry_run"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	report, err := services.RunRetentionNow(req.DryRun)
+	switch {
+	case errors.Is(err, services.ErrRetentionInProgress):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "The retention job is already running, try again later",
+		})
+	case err != nil && report == nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to run retention",
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":  err.Error(),
+			"report": report,
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// GetRetentionRuns lists the recorded retention runs with what they deleted, newest first (ADMIN ONLY)
+// GET /v1/admin/retention-runs?status=failed&limit=50&offset=0
+func GetRetentionRuns(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > maxImportRunsLimit {
+		limit = maxImportRunsLimit
+	}
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := config.DB.Model(&models.RetentionRun{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count retention runs",
+		})
+	}
+
+	var runs []models.RetentionRun
+	if err := query.Preload("Items").Order("started_at DESC, id DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch retention runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"retention_runs": runs,
+		"count":          len(runs),
+		"total":          total,
+		"limit":          limit,
+		"offset":         offset,
+	})
+}
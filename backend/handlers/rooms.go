@@ -8,6 +8,7 @@ import (
 	"github.com/nora-nak/backend/config"
 	"github.com/nora-nak/backend/middleware"
 	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
 )
 
 // RoomOccupancyEvent represents a room occupancy event
@@ -22,6 +23,7 @@ type RoomOccupancyEvent struct {
 type RoomDetailResponse struct {
 	Room      RoomResponse         `json:"room"`
 	Occupancy []RoomOccupancyEvent `json:"occupancy"`
+	TimeZone  string               `json:"time_zone"` // Zone of the day boundaries
 }
 
 // FreeRoomsResponse represents free rooms query result
@@ -58,7 +60,8 @@ func GetRooms(c *fiber.Ctx) error {
 }
 
 // GetRoomDetails returns room details including occupancy
-// GET /v1/room?room_number=D102
+// GET /v1/room?room_number=D102&tz=Europe/Berlin
+// The occupancy covers today and the next 7 days in the tenant's time zone or tz.
 func GetRoomDetails(c *fiber.Ctx) error {
 	roomNumber := c.Query("room_number")
 	if roomNumber == "" {
@@ -67,6 +70,13 @@ func GetRoomDetails(c *fiber.Ctx) error {
 		})
 	}
 
+	loc, err := requestLocation(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
+
 	// Find room within tenant
 	tenantID := middleware.GetCurrentTenantID(c)
 	var room models.Room
@@ -77,16 +87,16 @@ func GetRoomDetails(c *fiber.Ctx) error {
 	}
 
 	// Time range: Today + 7 days
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	now := time.Now().In(loc)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 	endOfWeek := startOfDay.AddDate(0, 0, 7)
 
 	occupancy := make([]RoomOccupancyEvent, 0)
 
-	// Timetable events for this room
+	// Timetable events in this room, including events with several rooms (cancelled events don't occupy it)
 	var timetables []models.Timetable
-	config.DB.Where("room_id = ? AND start_time >= ? AND start_time <= ?",
-		room.ID, startOfDay, endOfWeek).Order("start_time").Find(&timetables)
+	config.DB.Where("id IN (?) AND status = ? AND start_time >= ? AND start_time <= ?",
+		timetablesInRoom(room.ID), models.TimetableStatusActive, startOfDay, endOfWeek).Order("start_time").Find(&timetables)
 
 	for _, tt := range timetables {
 		details := tt.Summary
@@ -125,6 +135,7 @@ func GetRoomDetails(c *fiber.Ctx) error {
 			RoomName:   room.RoomName,
 		},
 		Occupancy: occupancy,
+		TimeZone:  loc.String(),
 	})
 }
 
@@ -165,12 +176,12 @@ func GetFreeRooms(c *fiber.Ctx) error {
 	freeRooms := make([]RoomResponse, 0)
 
 	for _, room := range allRooms {
-		// Check for timetable conflicts
+		// Check for timetable conflicts (cancelled events don't block a room)
 		// Two time ranges overlap if: start1 < end2 AND end1 > start2
 		var timetableCount int64
 		config.DB.Model(&models.Timetable{}).Where(
-			"tenant_id = ? AND room_id = ? AND start_time < ? AND end_time > ?",
-			tenantID, room.ID, endTime, startTime,
+			"tenant_id = ? AND id IN (?) AND status = ? AND start_time < ? AND end_time > ?",
+			tenantID, timetablesInRoom(room.ID), models.TimetableStatusActive, endTime, startTime,
 		).Count(&timetableCount)
 
 		// Check for custom hour conflicts
@@ -200,6 +211,33 @@ func GetFreeRooms(c *fiber.Ctx) error {
 	})
 }
 
+// timetablesInRoom returns a subquery for the IDs of all timetable events taking place in a room
+func timetablesInRoom(roomID uint) *gorm.DB {
+	return config.DB.Model(&models.TimetableRoom{}).Select("timetable_id").Where("room_id = ?", roomID)
+}
+
+// withTimetableRooms preloads all rooms of timetable events in feed order
+func withTimetableRooms(db *gorm.DB) *gorm.DB {
+	return db.Preload("Rooms", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Rooms.Room").
+		Preload("Room")
+}
+
+// timetableRoomNumbers returns the room numbers of a timetable event, the primary room first
+// Requires the rooms loaded by withTimetableRooms
+func timetableRoomNumbers(tt *models.Timetable) []string {
+	numbers := make([]string, 0, len(tt.Rooms))
+	for _, link := range tt.Rooms {
+		if link.Room != nil {
+			numbers = append(numbers, link.Room.RoomNumber)
+		}
+	}
+	if len(numbers) == 0 && tt.Room != nil {
+		numbers = append(numbers, tt.Room.RoomNumber)
+	}
+	return numbers
+}
+
 // DeleteCustomHour deletes a custom hour
 // DELETE /v1/delete?session_id=...&custom_hour_id=123
 func DeleteCustomHour(c *fiber.Ctx) error {
@@ -1,13 +1,82 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/nora-nak/backend/middleware"
 	"github.com/nora-nak/backend/services"
 )
 
-// GetSchedulerStatus returns the scheduler status
+// GetSchedulerStatus returns the scheduler status with the last successful import and the
+// failing feed URLs of the tenant
 // GET /v1/scheduler/status?session_id=...
+// Instance names, feed URLs and fetch errors are only returned to admins.
 func GetSchedulerStatus(c *fiber.Ctx) error {
+	tenantID := middleware.GetCurrentTenantID(c)
 	status := services.GetSchedulerStatus()
+	status.LastSuccessfulRun = services.GetLastSuccessfulImportRun(tenantID)
+	status.FeedBreakers = services.GetFeedBreakers(tenantID)
+
+	if !middleware.HasRole(c, "admin") {
+		status.Instance = ""
+		for i := range status.Jobs {
+			status.Jobs[i].LockHolder = ""
+		}
+		for i := range status.FeedBreakers {
+			status.FeedBreakers[i].URL = ""
+			status.FeedBreakers[i].LastError = ""
+		}
+	}
+
 	return c.JSON(status)
 }
+
+// GetSchedulerJobs lists the scheduler jobs with their schedule and next run (ADMIN ONLY)
+// GET /v1/admin/scheduler/jobs
+func GetSchedulerJobs(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"jobs": services.GetSchedulerJobs(),
+	})
+}
+
+// UpdateSchedulerJob changes the schedule and/or enable flag of a scheduler job (ADMIN ONLY)
+// PUT /v1/admin/scheduler/jobs/:name
+// Body: {"schedule": "0 * * * *", "enabled": true}
+// The settings override the environment, an empty schedule resets it to the environment or default.
+func UpdateSchedulerJob(c *fiber.Ctx) error {
+	var req struct {
+		Schedule *string `json:"schedule"`
+		Enabled  *bool   `json:"enabled"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Schedule == nil && req.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "schedule or enabled is required",
+		})
+	}
+
+	job, err := services.UpdateSchedulerJob(c.Params("name"), req.Schedule, req.Enabled)
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Scheduler job not found",
+		})
+	case errors.Is(err, services.ErrInvalidSchedule):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update scheduler job",
+		})
+	}
+
+	return c.JSON(job)
+}
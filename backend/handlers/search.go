@@ -50,44 +50,42 @@ func Search(c *fiber.Ctx) error {
 	roomResults := make([]SearchResult, 0)
 	friendResults := make([]SearchResult, 0)
 
-	// 1. Search in Timetables (user's zenturie within tenant)
+	// 1. Search in Timetables (user's zenturie and course selection within tenant)
 	tenantID := middleware.GetCurrentTenantID(c)
-	if user.ZenturienID != nil {
-		var timetables []models.Timetable
-		config.DB.Where("tenant_id = ? AND zenturien_id = ?", tenantID, *user.ZenturienID).Find(&timetables)
+	var timetables []models.Timetable
+	config.DB.Scopes(personalTimetables(user)).Where("tenant_id = ?", tenantID).Find(&timetables)
 
-		for _, tt := range timetables {
-			score := getBestMatchScore(query,
-				tt.Summary,
-				strPtr(tt.Description),
-				strPtr(tt.Professor),
-				strPtr(tt.CourseCode),
-				strPtr(tt.Location),
-			)
+	for _, tt := range timetables {
+		score := getBestMatchScore(query,
+			tt.Summary,
+			strPtr(tt.Description),
+			strPtr(tt.Professor),
+			strPtr(tt.CourseCode),
+			strPtr(tt.Location),
+		)
 
-			if score >= minScore {
-				details := ""
-				if tt.Professor != nil {
-					details = "Professor: " + *tt.Professor
-				}
-
-				startTime := tt.StartTime.Format("2006-01-02T15:04:05")
-				location := ""
-				if tt.Location != nil {
-					location = *tt.Location
-				}
-
-				timetableResults = append(timetableResults, SearchResult{
-					ResultType:      "event",
-					ID:              tt.ID,
-					Name:            tt.Summary,
-					Details:         &details,
-					StartTime:       &startTime,
-					Location:        &location,
-					MatchPercentage: score * 100, // Convert to percentage
-					Score:           score,
-				})
+		if score >= minScore {
+			details := ""
+			if tt.Professor != nil {
+				details = "Professor: " + *tt.Professor
 			}
+
+			startTime := tt.StartTime.Format("2006-01-02T15:04:05")
+			location := ""
+			if tt.Location != nil {
+				location = *tt.Location
+			}
+
+			timetableResults = append(timetableResults, SearchResult{
+				ResultType:      "event",
+				ID:              tt.ID,
+				Name:            tt.Summary,
+				Details:         &details,
+				StartTime:       &startTime,
+				Location:        &location,
+				MatchPercentage: score * 100, // Convert to percentage
+				Score:           score,
+			})
 		}
 	}
 
@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/middleware"
 	"github.com/nora-nak/backend/models"
 	"github.com/nora-nak/backend/services"
 )
@@ -16,8 +20,9 @@ import (
 // CreateTenant creates a new tenant and corresponding Keycloak realm (ADMIN ONLY)
 func CreateTenant(c *fiber.Ctx) error {
 	var req struct {
-		Name string `json:"name" validate:"required,min=3,max=255"`
-		Slug string `json:"slug" validate:"required,min=3,max=50"`
+		Name     string `json:"name" validate:"required,min=3,max=255"`
+		Slug     string `json:"slug" validate:"required,min=3,max=50"`
+		TimeZone string `json:"time_zone"` // Optional, defaults to Europe/Berlin
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -33,6 +38,15 @@ func CreateTenant(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.TimeZone == "" {
+		req.TimeZone = services.DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "time_zone must be a valid IANA time zone (e.g. Europe/Berlin)",
+		})
+	}
+
 	// Check if slug already exists
 	var existingTenant models.Tenant
 	if err := config.DB.Where("slug = ?", req.Slug).First(&existingTenant).Error; err == nil {
@@ -48,6 +62,7 @@ func CreateTenant(c *fiber.Ctx) error {
 		KeycloakRealmID:  req.Slug + "-realm",
 		KeycloakURL:      config.AppConfig.KeycloakURL,
 		KeycloakClientID: "nora-frontend",
+		TimeZone:         req.TimeZone,
 		IsActive:         true,
 	}
 
@@ -117,6 +132,7 @@ func UpdateTenant(c *fiber.Ctx) error {
 
 	var req struct {
 		Name     string `json:"name"`
+		TimeZone string `json:"time_zone"`
 		IsActive *bool  `json:"is_active"`
 	}
 
@@ -137,6 +153,14 @@ func UpdateTenant(c *fiber.Ctx) error {
 	if req.Name != "" {
 		tenant.Name = req.Name
 	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "time_zone must be a valid IANA time zone (e.g. Europe/Berlin)",
+			})
+		}
+		tenant.TimeZone = req.TimeZone
+	}
 	if req.IsActive != nil {
 		tenant.IsActive = *req.IsActive
 	}
@@ -236,6 +260,461 @@ func GetTenantStats(c *fiber.Ctx) error {
 	})
 }
 
+// GetTenantFeedSource returns the timetable feed source of a tenant (ADMIN ONLY)
+func GetTenantFeedSource(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	source, err := services.GetFeedSource(tenant.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrNoFeedSource) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Tenant has no feed source configured",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch feed source",
+		})
+	}
+
+	return c.JSON(source)
+}
+
+// UpdateTenantFeedSource creates or updates the timetable feed source of a tenant (ADMIN ONLY)
+func UpdateTenantFeedSource(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var req struct {
+		BaseURL     string `json:"base_url"`
+		URLPattern  string `json:"url_pattern"`
+		MaxSemester *int   `json:"max_semester"`
+		Adapter     string `json:"adapter"`
+		Path        string `json:"path"`
+		Encoding    string `json:"encoding"`
+		IsActive    *bool  `json:"is_active"`
+
+		GuardMaxDropPercent *int `json:"guard_max_drop_percent"`
+		GuardMaxMovedEvents *int `json:"guard_max_moved_events"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	source, err := services.GetFeedSource(tenant.ID)
+	if err != nil {
+		if !errors.Is(err, services.ErrNoFeedSource) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch feed source",
+			})
+		}
+		source = &models.FeedSource{TenantID: tenant.ID, IsActive: true, GuardMaxDropPercent: 50}
+	}
+
+	// Update fields
+	if req.BaseURL != "" {
+		source.BaseURL = req.BaseURL
+	}
+	if req.URLPattern != "" {
+		if !strings.Contains(req.URLPattern, "{zenturie}") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "url_pattern must contain the {zenturie} placeholder",
+			})
+		}
+		source.URLPattern = req.URLPattern
+	}
+	if req.MaxSemester != nil {
+		if *req.MaxSemester < 1 || *req.MaxSemester > 20 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "max_semester must be between 1 and 20",
+			})
+		}
+		source.MaxSemester = *req.MaxSemester
+	}
+	if req.Adapter != "" {
+		if !services.IsValidAdapter(req.Adapter) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "adapter must be one of ics_url, local_dir, upload, json",
+			})
+		}
+		source.Adapter = req.Adapter
+	}
+	if req.Path != "" {
+		if _, err := services.ResolveFeedSourcePath(req.Path); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "path must be inside the feed source directory",
+			})
+		}
+		source.Path = req.Path
+	}
+	if req.Encoding != "" {
+		if req.Encoding != services.EncodingLatin1 && req.Encoding != services.EncodingUTF8 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "encoding must be latin1 or utf-8",
+			})
+		}
+		source.Encoding = req.Encoding
+	}
+	if req.IsActive != nil {
+		source.IsActive = *req.IsActive
+	}
+	if req.GuardMaxDropPercent != nil {
+		if *req.GuardMaxDropPercent < 0 || *req.GuardMaxDropPercent > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "guard_max_drop_percent must be between 0 and 100",
+			})
+		}
+		source.GuardMaxDropPercent = *req.GuardMaxDropPercent
+	}
+	if req.GuardMaxMovedEvents != nil {
+		if *req.GuardMaxMovedEvents < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "guard_max_moved_events must not be negative",
+			})
+		}
+		source.GuardMaxMovedEvents = *req.GuardMaxMovedEvents
+	}
+
+	if source.Adapter == services.AdapterLocalDir && source.Path == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "path is required for the local_dir adapter",
+		})
+	}
+	if source.Adapter == services.AdapterJSON && source.Path == "" && source.BaseURL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "path or base_url is required for the json adapter",
+		})
+	}
+
+	if (source.Adapter == "" || source.Adapter == services.AdapterICSURL) && source.BaseURL == "" && strings.Contains(source.URLPattern, "{base}") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "base_url is required",
+		})
+	}
+
+	if err := services.SaveFeedSource(source); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save feed source",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Feed source updated successfully",
+		"feed_source": source,
+	})
+}
+
+// GetTenantFeedUploads lists the uploaded ICS files of a tenant (ADMIN ONLY)
+func GetTenantFeedUploads(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	var uploads []models.FeedUpload
+	if err := config.DB.Where("tenant_id = ?", tenant.ID).Order("file_name ASC").Find(&uploads).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch uploaded files",
+		})
+	}
+
+	return c.JSON(uploads)
+}
+
+// UploadTenantFeedFile stores an ICS file for the "upload" adapter of a tenant (ADMIN ONLY)
+// Multipart form: file (<zenturie>_<semester>.ics), optional encoding (latin1 or utf-8)
+func UploadTenantFeedFile(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "file is required",
+		})
+	}
+
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".ics") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Only .ics files are supported",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read file",
+		})
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil || len(content) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read file",
+		})
+	}
+
+	encoding := c.FormValue("encoding", services.EncodingUTF8)
+	if encoding != services.EncodingLatin1 && encoding != services.EncodingUTF8 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "encoding must be latin1 or utf-8",
+		})
+	}
+
+	var uploadedBy *uint
+	if user := middleware.GetCurrentUser(c); user != nil {
+		uploadedBy = &user.ID
+	}
+
+	upload, err := services.SaveFeedUpload(tenant.ID, filepath.Base(fileHeader.Filename), content, encoding, uploadedBy)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "File uploaded successfully",
+		"upload":  upload,
+	})
+}
+
+// GetTenantExtractionRules returns the description/summary extraction rules of a tenant (ADMIN ONLY)
+// is_default is true if the tenant uses the built-in NAK rule set
+func GetTenantExtractionRules(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	rules, isDefault, err := services.GetExtractionRules(tenant.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch extraction rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"rules":      rules,
+		"is_default": isDefault,
+	})
+}
+
+// UpdateTenantExtractionRules replaces the extraction rules of a tenant (ADMIN ONLY)
+// Body: {"rules": [{"field": "professor", "source": "description", "kind": "label", "pattern": "Dozent:"}, ...]}
+// An empty list restores the default rule set. Changes apply from the next import.
+func UpdateTenantExtractionRules(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var req struct {
+		Rules []struct {
+			Field    string `json:"field"`
+			Source   string `json:"source"`
+			Kind     string `json:"kind"`
+			Pattern  string `json:"pattern"`
+			Group    *int   `json:"group"`
+			Position *int   `json:"position"`
+			IsActive *bool  `json:"is_active"`
+		} `json:"rules"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	rules := make([]models.ExtractionRule, 0, len(req.Rules))
+	for i, r := range req.Rules {
+		rule := models.ExtractionRule{
+			Field:    r.Field,
+			Source:   r.Source,
+			Kind:     r.Kind,
+			Pattern:  r.Pattern,
+			Group:    1,
+			Position: (i + 1) * 10, // Default: order of the request
+			IsActive: true,
+		}
+		if r.Group != nil {
+			rule.Group = *r.Group
+		}
+		if r.Position != nil {
+			rule.Position = *r.Position
+		}
+		if r.IsActive != nil {
+			rule.IsActive = *r.IsActive
+		}
+		rules = append(rules, rule)
+	}
+
+	if _, err := services.CompileExtractionRules(rules); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Invalid extraction rule: %v", err),
+		})
+	}
+
+	if err := services.SaveExtractionRules(tenant.ID, rules); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save extraction rules",
+		})
+	}
+
+	stored, isDefault, err := services.GetExtractionRules(tenant.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch extraction rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Extraction rules updated successfully",
+		"rules":      stored,
+		"is_default": isDefault,
+	})
+}
+
+// GetTenantRoomRules returns the room grammar of a tenant (ADMIN ONLY)
+// is_default is true if the tenant uses the built-in NAK grammar
+func GetTenantRoomRules(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	rules, isDefault, err := services.GetRoomRules(tenant.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch room rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"rules":      rules,
+		"is_default": isDefault,
+	})
+}
+
+// UpdateTenantRoomRules replaces the room grammar of a tenant (ADMIN ONLY)
+// Body: {"rules": [{"kind": "prefix", "pattern": "EDV-"}, {"kind": "alias", "pattern": "Audimax", "room_number": "Audimax"}, ...]}
+// Pattern rules are regular expressions with the named groups number, building and floor.
+// An empty list restores the default grammar. Changes apply from the next import.
+func UpdateTenantRoomRules(c *fiber.Ctx) error {
+	tenantID := c.Params("id")
+
+	var req struct {
+		Rules []struct {
+			Kind       string `json:"kind"`
+			Pattern    string `json:"pattern"`
+			RoomNumber string `json:"room_number"`
+			Building   string `json:"building"`
+			Floor      string `json:"floor"`
+			Uppercase  bool   `json:"uppercase"`
+			Position   *int   `json:"position"`
+			IsActive   *bool  `json:"is_active"`
+		} `json:"rules"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, tenantID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tenant not found",
+		})
+	}
+
+	rules := make([]models.RoomRule, 0, len(req.Rules))
+	for i, r := range req.Rules {
+		rule := models.RoomRule{
+			Kind:       r.Kind,
+			Pattern:    r.Pattern,
+			RoomNumber: r.RoomNumber,
+			Building:   r.Building,
+			Floor:      r.Floor,
+			Uppercase:  r.Uppercase,
+			Position:   (i + 1) * 10, // Default: order of the request
+			IsActive:   true,
+		}
+		if r.Position != nil {
+			rule.Position = *r.Position
+		}
+		if r.IsActive != nil {
+			rule.IsActive = *r.IsActive
+		}
+		rules = append(rules, rule)
+	}
+
+	if _, err := services.CompileRoomRules(rules); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Invalid room rule: %v", err),
+		})
+	}
+
+	if err := services.SaveRoomRules(tenant.ID, rules); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save room rules",
+		})
+	}
+
+	stored, isDefault, err := services.GetRoomRules(tenant.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch room rules",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Room rules updated successfully",
+		"rules":      stored,
+		"is_default": isDefault,
+	})
+}
+
 // isValidSlug validates slug format
 // Rules: 3-50 characters, lowercase letters, numbers, and hyphens only
 func isValidSlug(slug string) bool {
@@ -1,7 +1,9 @@
 package handlers
 
 import (
-	"sort"
+	"errors"
+	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/nora-nak/backend/config"
 	"github.com/nora-nak/backend/middleware"
 	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/services"
 )
 
 // UserResponse represents user information
@@ -24,8 +27,9 @@ type UserResponse struct {
 
 // ZenturieResponse represents zenturie information
 type ZenturieResponse struct {
-	Zenturie string `json:"zenturie"`
-	Year     string `json:"year"`
+	Zenturie       string `json:"zenturie"`
+	Year           string `json:"year"`
+	ActiveSemester *int   `json:"active_semester,omitempty"` // Highest semester with a feed file
 }
 
 // ZenturieSetRequest for setting user's zenturie
@@ -51,6 +55,42 @@ type ExamResponse struct {
 	Room         *string   `json:"room,omitempty"`
 }
 
+// EventResponse represents an event of an event list, event_type tells which fields are set
+// Fields of other event types are omitted.
+type EventResponse struct {
+	EventType   string    `json:"event_type"` // timetable, custom_hour, exam
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	Location    *string   `json:"location"`
+	Room        *string   `json:"room"`
+	Description *string   `json:"description"`
+
+	// Timetable events
+	UID         string     `json:"uid,omitempty"`
+	Professor   *string    `json:"professor,omitempty"`
+	CourseCode  *string    `json:"course_code,omitempty"`
+	OnlineLink  *string    `json:"online_link,omitempty"`
+	Notes       *string    `json:"notes,omitempty"`
+	Rooms       []string   `json:"rooms,omitempty"`
+	Color       *string    `json:"color,omitempty"`
+	BorderColor *string    `json:"border_color,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	IsAllDay    bool       `json:"is_all_day,omitempty"`
+	Zenturie    string     `json:"zenturie,omitempty"`  // Zenturie of the event (events and lecturer timetables)
+	Zenturien   []string   `json:"zenturien,omitempty"` // All zenturien attending the event (lecturer timetables)
+
+	// Custom hours
+	CustomLocation *string `json:"custom_location,omitempty"`
+
+	// Exams
+	ModuleNumber string `json:"module_number,omitempty"`
+	Duration     int    `json:"duration,omitempty"`
+	IsVerified   bool   `json:"is_verified,omitempty"`
+}
+
 // FriendResponse represents friend information
 type FriendResponse struct {
 	UserID    uint    `json:"user_id"`
@@ -145,12 +185,21 @@ func GetAllZenturien(c *fiber.Ctx) error {
 		})
 	}
 
+	// Active semester: highest semester with a feed file (omitted if unknown)
+	activeSemesters, err := services.GetActiveSemesters(tenantID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load active semesters of tenant %d: %v", tenantID, err)
+	}
+
 	response := make([]ZenturieResponse, len(zenturien))
 	for i, z := range zenturien {
 		response[i] = ZenturieResponse{
 			Zenturie: z.Name,
 			Year:     z.Year,
 		}
+		if semester, ok := activeSemesters[z.ID]; ok {
+			response[i].ActiveSemester = &semester
+		}
 	}
 
 	return c.JSON(response)
@@ -209,9 +258,12 @@ func GetCourses(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
-// GetEvents returns all events for a specific date or date range (timetables + custom hours)
+// GetEvents returns all events for a specific date or date range (timetables + custom hours, optionally exams)
+// Timetable events follow the user's course selection (enrolled electives, dropped courses).
 // GET /v1/events?session_id=...&date=2025-01-20
-// GET /v1/events?session_id=...&date=2025-01-01&end=2025-12-01
+// GET /v1/events?session_id=...&date=2025-01-01&end=2025-12-01&include=timetable,custom,exam&limit=100&cursor=...
+// Days are resolved in the tenant's time zone or tz (e.g. tz=Europe/Berlin).
+// With limit, the cursor of the next page is returned in the X-Next-Cursor header.
 func GetEvents(c *fiber.Ctx) error {
 	user := middleware.GetCurrentUser(c)
 
@@ -222,110 +274,77 @@ func GetEvents(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse start date in UTC
-	eventDate, err := time.Parse("2006-01-02", dateStr)
+	loc, err := requestLocation(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"detail": "Ungültiges Datumsformat. Nutze YYYY-MM-DD",
+			"detail": err.Error(),
 		})
 	}
 
-	// Start of day in UTC
-	startOfDay := time.Date(eventDate.Year(), eventDate.Month(), eventDate.Day(), 0, 0, 0, 0, time.UTC)
-
-	// Check if end parameter is provided (for date range queries)
-	endStr := c.Query("end")
-	var endOfDay time.Time
-
-	if endStr != "" {
-		// Parse end date in UTC
-		endDate, err := time.Parse("2006-01-02", endStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"detail": "Ungültiges End-Datumsformat. Nutze YYYY-MM-DD",
-			})
-		}
+	startOfDay, endOfDay, err := parseDateRange(dateStr, c.Query("end"), loc)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
 
-		// Validate that end date is not before start date
-		if endDate.Before(eventDate) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"detail": "End-Datum darf nicht vor Start-Datum liegen",
-			})
-		}
+	include, err := parseEventInclude(c.Query("include"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
 
-		// End of end date in UTC
-		endOfDay = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, time.UTC)
-	} else {
-		// No end parameter: use end of start date (single day query - backward compatible)
-		endOfDay = time.Date(eventDate.Year(), eventDate.Month(), eventDate.Day(), 23, 59, 59, 999999999, time.UTC)
+	page, err := parseEventPage(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
 	}
 
-	events := make([]map[string]interface{}, 0)
+	events := make([]EventResponse, 0)
 
-	// Timetable events for user's zenturie
+	// Timetable events of the user's zenturie and course selection
 	tenantID := middleware.GetCurrentTenantID(c)
-	if user.ZenturienID != nil {
+	if include[EventTypeTimetable] {
 		var timetables []models.Timetable
-		config.DB.Preload("Room").Where("tenant_id = ? AND zenturien_id = ? AND start_time >= ? AND start_time <= ?",
-			tenantID, *user.ZenturienID, startOfDay, endOfDay).Find(&timetables)
+		config.DB.Scopes(withTimetableRooms, personalTimetables(user), page.scope(EventTypeTimetable)).
+			Preload("Zenturie").
+			Where("tenant_id = ? AND start_time >= ? AND start_time <= ?", tenantID, startOfDay, endOfDay).
+			Find(&timetables)
 
-		for _, tt := range timetables {
-			var roomStr *string
-			if tt.Room != nil {
-				roomStr = &tt.Room.RoomNumber
-			}
-
-			events = append(events, map[string]interface{}{
-				"event_type":   "timetable",
-				"title":        tt.Summary,
-				"start_time":   tt.StartTime.UTC().Format(time.RFC3339),
-				"end_time":     tt.EndTime.UTC().Format(time.RFC3339),
-				"location":     roomStr,
-				"description":  tt.Description,
-				"uid":          tt.UID,
-				"professor":    tt.Professor,
-				"course_code":  tt.CourseCode,
-				"room":         roomStr,
-				"color":        tt.Color,
-				"border_color": tt.BorderColor,
-			})
+		for i := range timetables {
+			events = append(events, timetableEvent(&timetables[i]))
 		}
 	}
 
 	// Custom hours
-	var customHours []models.CustomHour
-	config.DB.Preload("Room").Where("user_id = ? AND start_time >= ? AND start_time <= ?",
-		user.ID, startOfDay, endOfDay).Find(&customHours)
-
-	for _, ch := range customHours {
-		var roomStr *string
-		if ch.Room != nil {
-			roomStr = &ch.Room.RoomNumber
-		} else if ch.CustomLocation != nil {
-			roomStr = ch.CustomLocation
+	if include[EventTypeCustomHour] {
+		var customHours []models.CustomHour
+		config.DB.Preload("Room").Scopes(page.scope(EventTypeCustomHour)).
+			Where("user_id = ? AND start_time >= ? AND start_time <= ?", user.ID, startOfDay, endOfDay).
+			Find(&customHours)
+
+		for i := range customHours {
+			events = append(events, customHourEvent(&customHours[i]))
 		}
-
-		events = append(events, map[string]interface{}{
-			"event_type":      "custom_hour",
-			"title":           ch.Title,
-			"start_time":      ch.StartTime.UTC().Format(time.RFC3339),
-			"end_time":        ch.EndTime.UTC().Format(time.RFC3339),
-			"description":     ch.Description,
-			"id":              ch.ID,
-			"room":            roomStr,
-			"custom_location": ch.CustomLocation,
-			"location":        roomStr,
-		})
 	}
 
-	// Sort by start_time
-	sort.Slice(events, func(i, j int) bool {
-		startTimeI, _ := time.Parse(time.RFC3339, events[i]["start_time"].(string))
-		startTimeJ, _ := time.Parse(time.RFC3339, events[j]["start_time"].(string))
-		return startTimeI.Before(startTimeJ)
-	})
+	// Exams of the user's study program and year, like GetExams
+	if include[EventTypeExam] && user.ZenturienID != nil {
+		if userIDs := studyYearUserIDs(*user.ZenturienID); len(userIDs) > 0 {
+			var exams []models.Exam
+			config.DB.Preload("Course").Preload("Room").Scopes(page.scope(EventTypeExam)).
+				Where("user_id IN ? AND start_time >= ? AND start_time <= ?", userIDs, startOfDay, endOfDay).
+				Find(&exams)
 
-	return c.JSON(events)
+			for i := range exams {
+				events = append(events, examEvent(&exams[i]))
+			}
+		}
+	}
+
+	return c.JSON(page.apply(c, events))
 }
 
 // GetExams returns all upcoming exams for the user's entire year (e.g., A24)
@@ -338,31 +357,11 @@ func GetExams(c *fiber.Ctx) error {
 		return c.JSON([]ExamResponse{})
 	}
 
-	// Get user's zenturie to find the study program + year
-	var zenturie models.Zenturie
-	if err := config.DB.First(&zenturie, *user.ZenturienID).Error; err != nil {
-		return c.JSON([]ExamResponse{})
-	}
-
-	// Extract study program + year from zenturie name (e.g., "I24c" -> "I24", "A24a" -> "A24")
-	// This is everything except the last character
-	zenturieName := zenturie.Name
-	if len(zenturieName) < 2 {
+	// Exams are shared by all users of the study program + year
+	userIDs := studyYearUserIDs(*user.ZenturienID)
+	if userIDs == nil {
 		return c.JSON([]ExamResponse{})
 	}
-	studyProgramAndYear := zenturieName[:len(zenturieName)-1] // Remove last character
-
-	// Find all zenturien with the same study program + year (e.g., all "A24*")
-	var zenturienIDs []uint
-	config.DB.Model(&models.Zenturie{}).
-		Where("name LIKE ?", studyProgramAndYear+"%").
-		Pluck("id", &zenturienIDs)
-
-	// Find all users in these zenturien
-	var userIDs []uint
-	config.DB.Model(&models.User{}).
-		Where("zenturien_id IN ?", zenturienIDs).
-		Pluck("id", &userIDs)
 
 	// Find all exams from these users
 	var exams []models.Exam
@@ -392,6 +391,37 @@ func GetExams(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
+// studyYearUserIDs returns the users of all zenturien with the same study program + year as the given zenturie
+// Returns nil if the zenturie is unknown or its name has no year
+func studyYearUserIDs(zenturienID uint) []uint {
+	// Get the zenturie to find the study program + year
+	var zenturie models.Zenturie
+	if err := config.DB.First(&zenturie, zenturienID).Error; err != nil {
+		return nil
+	}
+
+	// Extract study program + year from zenturie name (e.g., "I24c" -> "I24", "A24a" -> "A24")
+	// This is everything except the last character
+	zenturieName := zenturie.Name
+	if len(zenturieName) < 2 {
+		return nil
+	}
+	studyProgramAndYear := zenturieName[:len(zenturieName)-1] // Remove last character
+
+	// Find all zenturien with the same study program + year (e.g., all "A24*") of the same tenant
+	var zenturienIDs []uint
+	config.DB.Model(&models.Zenturie{}).
+		Where("tenant_id = ? AND name LIKE ?", zenturie.TenantID, studyProgramAndYear+"%").
+		Pluck("id", &zenturienIDs)
+
+	// Find all users in these zenturien
+	userIDs := make([]uint, 0)
+	config.DB.Model(&models.User{}).
+		Where("tenant_id = ? AND zenturien_id IN ?", zenturie.TenantID, zenturienIDs).
+		Pluck("id", &userIDs)
+	return userIDs
+}
+
 // GetFriends returns user's friend list
 // GET /v1/friends?session_id=...
 func GetFriends(c *fiber.Ctx) error {
@@ -528,7 +558,7 @@ func RemoveFriend(c *fiber.Ctx) error {
 }
 
 // ViewZenturieTimetable returns timetable for a specific zenturie (public, no auth)
-// GET /v1/view?zenturie=I24c&date=2025-01-20&end=2025-01-22
+// GET /v1/view?zenturie=I24c&date=2025-01-20&end=2025-01-22&tz=Europe/Berlin&limit=100&cursor=...
 func ViewZenturieTimetable(c *fiber.Ctx) error {
 	zenturieName := c.Query("zenturie")
 	dateStr := c.Query("date")
@@ -539,36 +569,25 @@ func ViewZenturieTimetable(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse date in UTC
-	eventDate, err := time.Parse("2006-01-02", dateStr)
+	loc, err := requestLocation(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"detail": "Ungültiges Datumsformat. Nutze YYYY-MM-DD",
+			"detail": err.Error(),
 		})
 	}
 
-	// Check if end parameter is provided (for date range queries)
-	endStr := c.Query("end")
-	var endDate time.Time
-
-	if endStr != "" {
-		// Parse end date in UTC
-		endDate, err = time.Parse("2006-01-02", endStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"detail": "Ungültiges End-Datumsformat. Nutze YYYY-MM-DD",
-			})
-		}
+	startOfDay, endOfDay, err := parseDateRange(dateStr, c.Query("end"), loc)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
+	}
 
-		// Validate that end date is not before start date
-		if endDate.Before(eventDate) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"detail": "End-Datum darf nicht vor Start-Datum liegen",
-			})
-		}
-	} else {
-		// No end parameter: use start date as end date
-		endDate = eventDate
+	page, err := parseEventPage(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"detail": err.Error(),
+		})
 	}
 
 	// Find zenturie within tenant
@@ -580,46 +599,65 @@ func ViewZenturieTimetable(c *fiber.Ctx) error {
 		})
 	}
 
-	// Time range in UTC
-	startOfDay := time.Date(eventDate.Year(), eventDate.Month(), eventDate.Day(), 0, 0, 0, 0, time.UTC)
-	endOfDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, time.UTC)
-
 	// Get timetables within tenant
 	var timetables []models.Timetable
-	config.DB.Preload("Room").Where("tenant_id = ? AND zenturien_id = ? AND start_time >= ? AND start_time <= ?",
-		tenantID, zenturie.ID, startOfDay, endOfDay).Find(&timetables)
+	config.DB.Scopes(withTimetableRooms, page.scope(EventTypeTimetable)).
+		Where("tenant_id = ? AND zenturien_id = ? AND start_time >= ? AND start_time <= ?",
+			tenantID, zenturie.ID, startOfDay, endOfDay).
+		Find(&timetables)
 
-	events := make([]map[string]interface{}, 0)
-	for _, tt := range timetables {
-		var roomStr *string
-		if tt.Room != nil {
-			roomStr = &tt.Room.RoomNumber
+	events := make([]EventResponse, 0, len(timetables))
+	for i := range timetables {
+		events = append(events, timetableEvent(&timetables[i]))
+	}
+
+	return c.JSON(page.apply(c, events))
+}
+
+// timeZoneHeader echoes the time zone day boundaries were resolved in
+const timeZoneHeader = "X-Time-Zone"
+
+// requestLocation returns the time zone of the optional tz query parameter (IANA name, e.g. Europe/Berlin),
+// or the tenant's time zone. The chosen zone is echoed in the X-Time-Zone header.
+func requestLocation(c *fiber.Ctx) (*time.Location, error) {
+	loc := services.TenantLocation(middleware.GetCurrentTenant(c))
+	if name := c.Query("tz"); name != "" {
+		var err error
+		loc, err = time.LoadLocation(name)
+		if err != nil || name == "Local" {
+			return nil, fmt.Errorf("Ungültige Zeitzone '%s'. Nutze einen IANA-Namen wie Europe/Berlin", name)
 		}
+	}
 
-		events = append(events, map[string]interface{}{
-			"event_type":   "timetable",
-			"title":        tt.Summary,
-			"start_time":   tt.StartTime.UTC().Format(time.RFC3339),
-			"end_time":     tt.EndTime.UTC().Format(time.RFC3339),
-			"location":     roomStr,
-			"description":  tt.Description,
-			"uid":          tt.UID,
-			"professor":    tt.Professor,
-			"course_code":  tt.CourseCode,
-			"room":         roomStr,
-			"color":        tt.Color,
-			"border_color": tt.BorderColor,
-		})
+	c.Set(timeZoneHeader, loc.String())
+	return loc, nil
+}
+
+// parseDateRange parses the date and optional end query parameters (YYYY-MM-DD) into a time range
+// The days start and end at midnight in loc, without end the range covers the single day of date
+func parseDateRange(dateStr, endStr string, loc *time.Location) (time.Time, time.Time, error) {
+	eventDate, err := time.ParseInLocation("2006-01-02", dateStr, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("Ungültiges Datumsformat. Nutze YYYY-MM-DD")
 	}
 
-	// Sort by start_time
-	sort.Slice(events, func(i, j int) bool {
-		startTimeI, _ := time.Parse(time.RFC3339, events[i]["start_time"].(string))
-		startTimeJ, _ := time.Parse(time.RFC3339, events[j]["start_time"].(string))
-		return startTimeI.Before(startTimeJ)
-	})
+	endDate := eventDate
+	if endStr != "" {
+		endDate, err = time.ParseInLocation("2006-01-02", endStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Ungültiges End-Datumsformat. Nutze YYYY-MM-DD")
+		}
+
+		// Validate that end date is not before start date
+		if endDate.Before(eventDate) {
+			return time.Time{}, time.Time{}, errors.New("End-Datum darf nicht vor Start-Datum liegen")
+		}
+	}
 
-	return c.JSON(events)
+	// Days are 23 or 25 hours long at DST changes, so the end is the next midnight
+	startOfDay := time.Date(eventDate.Year(), eventDate.Month(), eventDate.Day(), 0, 0, 0, 0, loc)
+	endOfDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day()+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+	return startOfDay.UTC(), endOfDay.UTC(), nil
 }
 
 // CreateCustomHour creates a new custom hour
@@ -18,6 +18,7 @@ import (
 	"github.com/nora-nak/backend/handlers"
 	"github.com/nora-nak/backend/middleware"
 	"github.com/nora-nak/backend/services"
+	"github.com/nora-nak/backend/utils"
 )
 
 func main() {
@@ -179,8 +180,19 @@ func setupProtectedRoutes(app *fiber.App) {
 
 	// Events & Timetables
 	protected.Get("/events", handlers.GetEvents)
+	protected.Get("/events/changes", handlers.GetEventChanges)
 	protected.Get("/exams", handlers.GetExams)
 
+	// Course Selection (electives from other zenturien, dropped courses)
+	protected.Get("/course-selections", handlers.GetCourseSelections)
+	protected.Post("/course-selections", handlers.AddCourseSelection)
+	protected.Delete("/course-selections", handlers.DeleteCourseSelection)
+	protected.Get("/course-selections/series", handlers.GetEventSeries)
+
+	// Lecturers
+	protected.Get("/lecturers", handlers.GetLecturers)
+	protected.Get("/lecturers/:id/timetable", handlers.GetLecturerTimetable)
+
 	// Friends (v1 - deprecated, kept for backwards compatibility)
 	var path = "/friends"
 	protected.Get(path, handlers.GetFriends)
@@ -223,6 +235,32 @@ func setupProtectedRoutes(app *fiber.App) {
 	admin.Put("/tenants/:id", handlers.UpdateTenant)
 	admin.Delete("/tenants/:id", handlers.DeleteTenant)
 	admin.Get("/tenants/:id/stats", handlers.GetTenantStats)
+	admin.Get("/tenants/:id/feed-source", handlers.GetTenantFeedSource)
+	admin.Put("/tenants/:id/feed-source", handlers.UpdateTenantFeedSource)
+	admin.Get("/tenants/:id/feed-uploads", handlers.GetTenantFeedUploads)
+	admin.Post("/tenants/:id/feed-uploads", handlers.UploadTenantFeedFile)
+	admin.Get("/tenants/:id/feed-snapshots", handlers.GetTenantFeedSnapshots)
+	admin.Get("/tenants/:id/extraction-rules", handlers.GetTenantExtractionRules)
+	admin.Put("/tenants/:id/extraction-rules", handlers.UpdateTenantExtractionRules)
+	admin.Get("/tenants/:id/room-rules", handlers.GetTenantRoomRules)
+	admin.Put("/tenants/:id/room-rules", handlers.UpdateTenantRoomRules)
+	admin.Get("/tenants/:id/retention", handlers.GetTenantRetention)
+	admin.Put("/tenants/:id/retention", handlers.UpdateTenantRetention)
+	admin.Post("/tenants/:id/import", handlers.TriggerTenantImport)
+	admin.Get("/tenants/:id/lecturers", handlers.GetTenantLecturers)
+	admin.Post("/tenants/:id/lecturers/merge", handlers.MergeTenantLecturers)
+	admin.Get("/import-runs", handlers.GetImportRuns)
+	admin.Get("/import-runs/:id", handlers.GetImportRun)
+	admin.Get("/import-quarantines", handlers.GetImportQuarantines)
+	admin.Get("/import-quarantines/:id", handlers.GetImportQuarantine)
+	admin.Post("/import-quarantines/:id/approve", handlers.ApproveImportQuarantine)
+	admin.Post("/import-quarantines/:id/reject", handlers.RejectImportQuarantine)
+	admin.Get("/feed-snapshots/:id", handlers.GetFeedSnapshot)
+	admin.Post("/feed-snapshots/:id/replay", handlers.ReplayFeedSnapshot)
+	admin.Get("/scheduler/jobs", handlers.GetSchedulerJobs)
+	admin.Put("/scheduler/jobs/:name", handlers.UpdateSchedulerJob)
+	admin.Post("/retention/run", handlers.RunRetention)
+	admin.Get("/retention-runs", handlers.GetRetentionRuns)
 
 	// Teacher Routes (requires teacher or admin role)
 	teacher := protected.Group("/teacher", middleware.RequireRole("teacher", "admin"))
@@ -245,15 +283,9 @@ func customErrorHandler(c *fiber.Ctx, err error) error {
 
 // setupLogging configures logging to write to both console and file
 func setupLogging() error {
-	// Create logs directory if it doesn't exist
-	logDir := "logs"
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return err
-	}
-
-	// Open log file
-	logFile := filepath.Join(logDir, "backend_logs.log")
-	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// Open log file (creates the logs directory), rotated daily to logs/backend_logs-<date>.log
+	logFile := filepath.Join(utils.LogDir, "backend_logs.log")
+	file, err := utils.OpenDailyLogFile(logFile)
 	if err != nil {
 		return err
 	}
@@ -9,11 +9,12 @@ import (
 // Tenant represents a school/institution with its own Keycloak realm
 type Tenant struct {
 	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name             string    `gorm:"not null;size:255" json:"name"`               // "Nordakademie Hamburg"
-	Slug             string    `gorm:"uniqueIndex;not null;size:100" json:"slug"`   // "nordakademie-hh"
-	KeycloakRealmID  string    `gorm:"not null;size:255" json:"keycloak_realm_id"`  // "nordakademie-hh-realm"
-	KeycloakURL      string    `gorm:"not null;size:500" json:"keycloak_url"`       // "https://keycloak.nora-nak.de"
-	KeycloakClientID string    `gorm:"not null;size:255" json:"keycloak_client_id"` // "nora-backend"
+	Name             string    `gorm:"not null;size:255" json:"name"`                             // "Nordakademie Hamburg"
+	Slug             string    `gorm:"uniqueIndex;not null;size:100" json:"slug"`                 // "nordakademie-hh"
+	KeycloakRealmID  string    `gorm:"not null;size:255" json:"keycloak_realm_id"`                // "nordakademie-hh-realm"
+	KeycloakURL      string    `gorm:"not null;size:500" json:"keycloak_url"`                     // "https://keycloak.nora-nak.de"
+	KeycloakClientID string    `gorm:"not null;size:255" json:"keycloak_client_id"`               // "nora-backend"
+	TimeZone         string    `gorm:"not null;size:64;default:'Europe/Berlin'" json:"time_zone"` // IANA zone for floating feed times and day boundaries
 	IsActive         bool      `gorm:"default:true;not null" json:"is_active"`
 	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
@@ -24,8 +25,187 @@ type Tenant struct {
 	Courses    []Course    `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
 	Rooms      []Room      `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
 	Timetables []Timetable `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	FeedSource *FeedSource `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
+// FeedSource describes where a tenant's timetable feeds are fetched from
+type FeedSource struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID    uint      `gorm:"uniqueIndex;not null" json:"tenant_id"`
+	BaseURL     string    `gorm:"not null;size:500" json:"base_url"`                                               // "https://cis.nordakademie.de/fileadmin/Infos/Stundenplaene"
+	URLPattern  string    `gorm:"not null;size:500;default:'{base}/{zenturie}_{semester}.ics'" json:"url_pattern"` // Placeholders: {base}, {zenturie}, {semester}
+	MaxSemester int       `gorm:"not null;default:7" json:"max_semester"`                                          // Semesters 1..MaxSemester are probed per zenturie
+	Adapter     string    `gorm:"not null;size:20;default:'ics_url'" json:"adapter"`                               // ics_url, local_dir, upload, json
+	Path        string    `gorm:"size:500" json:"path,omitempty"`                                                  // Directory (local_dir) or file (json) on the server
+	Encoding    string    `gorm:"not null;size:20;default:'latin1'" json:"encoding"`                               // latin1 (NAK feeds) or utf-8
+	IsActive    bool      `gorm:"default:true;not null" json:"is_active"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Import sanity guards, a file violating one is quarantined instead of imported (0 disables a guard)
+	GuardMaxDropPercent int `gorm:"not null;default:50" json:"guard_max_drop_percent"` // Max. drop of the file's event count in percent
+	GuardMaxMovedEvents int `gorm:"not null;default:0" json:"guard_max_moved_events"`  // Max. number of existing events moved to another time
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// FeedFetchState stores HTTP caching metadata of a fetched feed URL
+// Used for conditional requests (If-None-Match/If-Modified-Since) and content hash comparison
+type FeedFetchState struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID      uint      `gorm:"not null;uniqueIndex:idx_tenant_feed_url" json:"tenant_id"`
+	URL           string    `gorm:"not null;size:1000;uniqueIndex:idx_tenant_feed_url" json:"url"`
+	ETag          *string   `gorm:"size:255" json:"etag,omitempty"`
+	LastModified  *string   `gorm:"size:255" json:"last_modified,omitempty"`
+	ContentHash   string    `gorm:"size:64;not null" json:"content_hash"` // SHA-256 of the raw response body
+	LastFetchedAt time.Time `gorm:"not null" json:"last_fetched_at"`
+	LastChangedAt time.Time `gorm:"not null" json:"last_changed_at"`
+
+	// Last time the recurring events (RRULE/RDATE) of the file were expanded, nil if it has none
+	RecurrenceExpandedAt *time.Time `json:"recurrence_expanded_at,omitempty"`
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// FeedCircuitBreaker counts consecutive failed fetches of a feed URL
+// After ICS_BREAKER_THRESHOLD failed runs the URL is paused until OpenUntil, afterwards one
+// attempt decides whether it stays paused. A successful fetch deletes the row.
+type FeedCircuitBreaker struct {
+	ID                  uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID            uint       `gorm:"not null;uniqueIndex:idx_tenant_breaker_url" json:"tenant_id"`
+	URL                 string     `gorm:"not null;size:1000;uniqueIndex:idx_tenant_breaker_url" json:"url"`
+	Zenturie            string     `gorm:"not null;size:50" json:"zenturie"`
+	SourceFile          string     `gorm:"not null;size:255" json:"source_file"`
+	ConsecutiveFailures int        `gorm:"not null;default:0" json:"consecutive_failures"`
+	LastError           string     `gorm:"type:text" json:"last_error"`
+	LastFailureAt       time.Time  `gorm:"not null" json:"last_failure_at"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"` // First opened, kept until the URL recovers
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// FeedUpload stores an ICS file uploaded by an admin, imported by the "upload" adapter
+// Uploading a file with the same name replaces the previous version
+type FeedUpload struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID   uint      `gorm:"not null;uniqueIndex:idx_tenant_upload_file" json:"tenant_id"`
+	FileName   string    `gorm:"not null;size:255;uniqueIndex:idx_tenant_upload_file" json:"file_name"` // e.g. "I24c_3.ics"
+	Zenturie   string    `gorm:"not null;size:50" json:"zenturie"`
+	Content    string    `gorm:"type:text;not null" json:"-"`
+	Size       int       `gorm:"not null" json:"size"`
+	UploadedBy *uint     `json:"uploaded_by,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// FeedSnapshot records the content a feed file had when it was fetched
+// A snapshot is stored when the content differs from the file's previous snapshot, so the
+// content at any time is the latest snapshot fetched before it.
+type FeedSnapshot struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID    uint      `gorm:"not null;index:idx_tenant_snapshot_file" json:"tenant_id"`
+	Zenturie    string    `gorm:"not null;size:50;index:idx_tenant_snapshot_file" json:"zenturie"`
+	SourceFile  string    `gorm:"not null;size:255;index:idx_tenant_snapshot_file" json:"source_file"`
+	URL         string    `gorm:"size:1000" json:"url,omitempty"`
+	ContentHash string    `gorm:"not null;size:64;index" json:"content_hash"` // SHA-256 of the UTF-8 content
+	ImportRunID *uint     `gorm:"index" json:"import_run_id,omitempty"`
+	FetchedAt   time.Time `gorm:"not null;index:idx_tenant_snapshot_file" json:"fetched_at"`
+
+	// Relationships
+	Tenant  *Tenant              `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	Content *FeedSnapshotContent `gorm:"foreignKey:ContentHash;references:ContentHash" json:"-"`
+}
+
+// FeedSnapshotContent is a gzip compressed feed payload, shared by all snapshots with the same content
+type FeedSnapshotContent struct {
+	ContentHash    string    `gorm:"primaryKey;size:64" json:"content_hash"`
+	Data           []byte    `gorm:"type:bytea;not null" json:"-"`
+	Size           int       `gorm:"not null" json:"size"`            // Uncompressed size in bytes
+	CompressedSize int       `gorm:"not null" json:"compressed_size"` // Size of Data in bytes
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ExtractionRule maps a label or regex capture of an imported event to a timetable field
+// Rules of a tenant are applied in Position order, the first match per field wins.
+// Tenants without rules use the built-in NAK rule set (see services.DefaultExtractionRules).
+type ExtractionRule struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID  uint      `gorm:"index;not null" json:"tenant_id"`
+	Field     string    `gorm:"type:varchar(30);not null" json:"field"`  // professor, room, course_type, course_code, online_link, notes
+	Source    string    `gorm:"type:varchar(20);not null" json:"source"` // summary, description, location
+	Kind      string    `gorm:"type:varchar(20);not null" json:"kind"`   // label, regex, value
+	Pattern   string    `gorm:"type:text" json:"pattern"`                // Label text (e.g. "Dozent:") or regular expression
+	Group     int       `gorm:"not null;default:1" json:"group"`         // Capture group of regex rules
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Extraction rule target fields
+const (
+	ExtractFieldProfessor  = "professor"
+	ExtractFieldRoom       = "room"
+	ExtractFieldCourseType = "course_type"
+	ExtractFieldCourseCode = "course_code"
+	ExtractFieldOnlineLink = "online_link"
+	ExtractFieldNotes      = "notes"
+)
+
+// Extraction rule sources (event properties)
+const (
+	ExtractSourceSummary     = "summary"
+	ExtractSourceDescription = "description"
+	ExtractSourceLocation    = "location"
+)
+
+// Extraction rule kinds
+const (
+	ExtractKindLabel = "label" // Text after Pattern up to the end of the line
+	ExtractKindRegex = "regex" // Capture group Group of the regular expression Pattern
+	ExtractKindValue = "value" // The whole source value
+)
+
+// RoomRule is one entry of a tenant's room grammar, used to turn feed locations into rooms
+// Aliases are checked first, then prefixes are stripped and patterns are tried in Position order.
+// Tenants without rules use the built-in NAK grammar (see services.DefaultRoomRules).
+type RoomRule struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID   uint      `gorm:"index;not null" json:"tenant_id"`
+	Kind       string    `gorm:"type:varchar(20);not null" json:"kind"` // pattern, alias, prefix
+	Pattern    string    `gorm:"type:text;not null" json:"pattern"`     // Regex (pattern), location text (alias) or prefix text (prefix)
+	RoomNumber string    `gorm:"size:100" json:"room_number,omitempty"` // alias: canonical room number, e.g. "Audimax"
+	Building   string    `gorm:"size:50" json:"building,omitempty"`     // alias: building of the room
+	Floor      string    `gorm:"size:50" json:"floor,omitempty"`        // alias: floor of the room
+	Uppercase  bool      `gorm:"not null;default:false" json:"uppercase"`
+	Position   int       `gorm:"not null;default:0" json:"position"`
+	IsActive   bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Room rule kinds
+const (
+	RoomRulePattern = "pattern" // Regex with the named groups number, building and floor
+	RoomRuleAlias   = "alias"   // Fixed location text mapped to RoomNumber/Building/Floor
+	RoomRulePrefix  = "prefix"  // Text stripped from the start of a location, e.g. "EDV-"
+)
+
 // Zenturie represents a class/cohort (e.g., I24c, A24b)
 type Zenturie struct {
 	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -33,10 +213,29 @@ type Zenturie struct {
 	Name     string `gorm:"not null;uniqueIndex:idx_tenant_zenturie_name" json:"name"`
 	Year     string `gorm:"not null" json:"year"`
 
+	// Feed discovery
+	SemesterDiscoveryAt *time.Time `json:"-"` // Last probe of all semester files, nil = probe on the next import
+
 	// Relationships
-	Tenant     *Tenant     `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
-	Users      []User      `gorm:"foreignKey:ZenturienID" json:"-"`
-	Timetables []Timetable `gorm:"foreignKey:ZenturienID" json:"-"`
+	Tenant     *Tenant            `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Users      []User             `gorm:"foreignKey:ZenturienID" json:"-"`
+	Timetables []Timetable        `gorm:"foreignKey:ZenturienID" json:"-"`
+	Semesters  []ZenturieSemester `gorm:"foreignKey:ZenturienID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ZenturieSemester is a semester file that exists in the feed of a zenturie, e.g. "I24c_3.ics"
+// Regular imports only fetch known files, all semesters are probed on the slower discovery schedule.
+type ZenturieSemester struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID    uint      `gorm:"index;not null" json:"tenant_id"`
+	ZenturienID uint      `gorm:"not null;uniqueIndex:idx_zenturie_semester" json:"zenturien_id"`
+	Semester    int       `gorm:"not null;uniqueIndex:idx_zenturie_semester" json:"semester"`
+	FirstSeenAt time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"not null" json:"last_seen_at"` // Last successful fetch (200 or 304)
+
+	// Relationships
+	Tenant   *Tenant   `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	Zenturie *Zenturie `gorm:"foreignKey:ZenturienID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
 // User represents a user authenticated via Keycloak
@@ -74,6 +273,24 @@ type Course struct {
 	Exams      []Exam      `gorm:"foreignKey:CourseID" json:"-"`
 }
 
+// Lecturer represents a lecturer, created from the professor field of imported events
+// Name variants with the same NormalizedName ("Prof. Dr. Müller", "Müller") are one lecturer.
+// Merged lecturers are kept as redirects, so the import keeps assigning their names to MergedIntoID.
+type Lecturer struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint      `gorm:"index;not null;uniqueIndex:idx_tenant_lecturer_name" json:"tenant_id"`
+	Name           string    `gorm:"not null" json:"name"`                                                 // Most complete name seen, e.g. "Prof. Dr. Müller"
+	NormalizedName string    `gorm:"not null;uniqueIndex:idx_tenant_lecturer_name" json:"normalized_name"` // e.g. "müller"
+	MergedIntoID   *uint     `gorm:"index" json:"merged_into_id,omitempty"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Tenant     *Tenant     `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	MergedInto *Lecturer   `gorm:"foreignKey:MergedIntoID;constraint:OnDelete:SET NULL" json:"-"`
+	Timetables []Timetable `gorm:"foreignKey:LecturerID" json:"-"`
+}
+
 // Room represents a university room
 type Room struct {
 	ID         uint    `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -84,10 +301,11 @@ type Room struct {
 	RoomName   *string `json:"room_name,omitempty"`
 
 	// Relationships
-	Tenant      *Tenant      `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
-	Timetables  []Timetable  `gorm:"foreignKey:RoomID" json:"-"`
-	CustomHours []CustomHour `gorm:"foreignKey:RoomID" json:"-"`
-	Exams       []Exam       `gorm:"foreignKey:RoomID" json:"-"`
+	Tenant      *Tenant         `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Timetables  []Timetable     `gorm:"foreignKey:RoomID" json:"-"`
+	Events      []TimetableRoom `gorm:"foreignKey:RoomID" json:"-"`
+	CustomHours []CustomHour    `gorm:"foreignKey:RoomID" json:"-"`
+	Exams       []Exam          `gorm:"foreignKey:RoomID" json:"-"`
 }
 
 // Timetable represents a timetable entry
@@ -97,6 +315,7 @@ type Timetable struct {
 	ZenturienID uint  `gorm:"index;not null;uniqueIndex:idx_tenant_uid_zenturie" json:"zenturien_id"`
 	CourseID    *uint `gorm:"index" json:"course_id,omitempty"`
 	RoomID      *uint `gorm:"index" json:"room_id,omitempty"`
+	LecturerID  *uint `gorm:"index" json:"lecturer_id,omitempty"`
 
 	// ICS/JSON Import Fields
 	UID         string    `gorm:"not null;uniqueIndex:idx_tenant_uid_zenturie" json:"uid"`
@@ -105,10 +324,13 @@ type Timetable struct {
 	Location    *string   `json:"location,omitempty"`
 	StartTime   time.Time `gorm:"index;not null" json:"start_time"`
 	EndTime     time.Time `gorm:"not null" json:"end_time"`
+	IsAllDay    bool      `gorm:"not null;default:false" json:"is_all_day"` // Date-only event (VALUE=DATE), times are local midnights
 
 	// Extracted/Additional Fields
 	Professor  *string `json:"professor,omitempty"`
 	CourseType *string `json:"course_type,omitempty"`
+	OnlineLink *string `gorm:"size:1000" json:"online_link,omitempty"`
+	Notes      *string `gorm:"type:text" json:"notes,omitempty"`
 
 	// ICS Metadata
 	DTStamp    *string `json:"dtstamp,omitempty"`
@@ -120,13 +342,250 @@ type Timetable struct {
 	Color       *string `json:"color,omitempty"`
 	BorderColor *string `json:"border_color,omitempty"`
 
+	// Feed Reconciliation
+	SourceFile  *string    `gorm:"size:255;index" json:"source_file,omitempty"`                    // ICS file the event was imported from, e.g. "I24c_3.ics"
+	Status      string     `gorm:"type:varchar(20);not null;default:'active';index" json:"status"` // active, cancelled
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`                                         // When the event vanished from its feed
+
 	// Relationships
-	Tenant   *Tenant   `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
-	Zenturie *Zenturie `gorm:"foreignKey:ZenturienID;constraint:OnDelete:CASCADE" json:"zenturie,omitempty"`
-	Course   *Course   `gorm:"foreignKey:CourseID;constraint:OnDelete:SET NULL" json:"course,omitempty"`
-	Room     *Room     `gorm:"foreignKey:RoomID;constraint:OnDelete:SET NULL" json:"room,omitempty"`
+	Tenant   *Tenant         `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Zenturie *Zenturie       `gorm:"foreignKey:ZenturienID;constraint:OnDelete:CASCADE" json:"zenturie,omitempty"`
+	Course   *Course         `gorm:"foreignKey:CourseID;constraint:OnDelete:SET NULL" json:"course,omitempty"`
+	Room     *Room           `gorm:"foreignKey:RoomID;constraint:OnDelete:SET NULL" json:"room,omitempty"`
+	Rooms    []TimetableRoom `gorm:"foreignKey:TimetableID;constraint:OnDelete:CASCADE" json:"rooms,omitempty"` // All rooms, RoomID is the first one
+	Lecturer *Lecturer       `gorm:"foreignKey:LecturerID;constraint:OnDelete:SET NULL" json:"lecturer,omitempty"`
 }
 
+// TimetableRoom assigns a room to a timetable event
+// Events can take place in several rooms at once (e.g. "A103, EDV-A102")
+type TimetableRoom struct {
+	ID          uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	TimetableID uint `gorm:"not null;uniqueIndex:idx_timetable_room" json:"timetable_id"`
+	RoomID      uint `gorm:"not null;index;uniqueIndex:idx_timetable_room" json:"room_id"`
+	Position    int  `gorm:"not null;default:0" json:"position"` // Order of the rooms in the feed
+
+	// Relationships
+	Timetable *Timetable `gorm:"foreignKey:TimetableID;constraint:OnDelete:CASCADE" json:"-"`
+	Room      *Room      `gorm:"foreignKey:RoomID;constraint:OnDelete:CASCADE" json:"room,omitempty"`
+}
+
+// Timetable status values
+const (
+	TimetableStatusActive    = "active"    // Event is present in its feed
+	TimetableStatusCancelled = "cancelled" // Event was removed from its feed
+)
+
+// ImportRun represents one execution of the timetable import pipeline for a tenant
+type ImportRun struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID   uint       `gorm:"index;not null" json:"tenant_id"`
+	Trigger    string     `gorm:"type:varchar(20);not null;default:'cron'" json:"trigger"`   // cron, manual, startup, approval, replay
+	Status     string     `gorm:"type:varchar(20);not null;default:'running'" json:"status"` // running, success, partial, failed
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMs *int64     `json:"duration_ms,omitempty"`
+	Error      *string    `gorm:"type:text" json:"error,omitempty"`
+
+	// Statistics
+	FilesDownloaded       int `gorm:"not null;default:0" json:"files_downloaded"`
+	FilesSkippedUnchanged int `gorm:"not null;default:0" json:"files_skipped_unchanged"`
+	FilesFailed           int `gorm:"not null;default:0" json:"files_failed"`
+	EventsCreated         int `gorm:"not null;default:0" json:"events_created"`
+	EventsUpdated         int `gorm:"not null;default:0" json:"events_updated"`
+	EventsUnchanged       int `gorm:"not null;default:0" json:"events_unchanged"`
+	EventsCancelled       int `gorm:"not null;default:0" json:"events_cancelled"`
+	FilesQuarantined      int `gorm:"not null;default:0" json:"files_quarantined"`
+	Errors                int `gorm:"not null;default:0" json:"errors"`
+
+	// Relationships
+	Tenant    *Tenant                   `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	Zenturien []ImportRunZenturieResult `gorm:"foreignKey:ImportRunID;constraint:OnDelete:CASCADE" json:"zenturien,omitempty"`
+	Files     []ImportRunFileResult     `gorm:"foreignKey:ImportRunID;constraint:OnDelete:CASCADE" json:"files,omitempty"`
+}
+
+// Import run status values
+const (
+	ImportRunStatusRunning = "running"
+	ImportRunStatusSuccess = "success"
+	ImportRunStatusPartial = "partial" // Finished, but some files or events could not be imported
+	ImportRunStatusFailed  = "failed"
+)
+
+// Import run triggers
+const (
+	ImportTriggerCron     = "cron"     // Scheduled run of the ics_import job
+	ImportTriggerStartup  = "startup"  // Immediate run when the server starts
+	ImportTriggerManual   = "manual"   // Started by an admin
+	ImportTriggerApproval = "approval" // Quarantined file approved by an admin
+	ImportTriggerReplay   = "replay"   // Archived feed snapshot re-imported by an admin
+)
+
+// SchedulerJob stores the admin settings of a scheduler job
+// Set values override the job's default and the JOB_<NAME>_* environment variables
+type SchedulerJob struct {
+	Name      string    `gorm:"primaryKey;size:50" json:"name"`
+	Schedule  *string   `gorm:"size:100" json:"schedule,omitempty"` // Cron expression, nil keeps the default schedule
+	Enabled   *bool     `json:"enabled,omitempty"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RetentionPolicy holds the retention periods of a tenant's data in days, 0 keeps the data forever
+// Tenants without a policy use the defaults of the retention job
+type RetentionPolicy struct {
+	ID                uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID          uint      `gorm:"uniqueIndex;not null" json:"tenant_id"`
+	TimetableDays     int       `gorm:"not null;default:365" json:"timetable_days"`     // Events, counted from their end
+	FriendRequestDays int       `gorm:"not null;default:30" json:"friend_request_days"` // Rejected friend requests, counted from the rejection
+	CustomHourDays    int       `gorm:"not null;default:180" json:"custom_hour_days"`   // Custom hours, counted from their end
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// RetentionRun records a run of the retention job (audit log of deleted data)
+// Dry runs are not recorded
+type RetentionRun struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Trigger    string     `gorm:"type:varchar(20);not null" json:"trigger"`      // cron, manual
+	Instance   string     `gorm:"size:255" json:"instance"`                      // Instance that ran it (log files are per instance)
+	Status     string     `gorm:"type:varchar(20);not null;index" json:"status"` // success, failed
+	Error      *string    `gorm:"type:text" json:"error,omitempty"`
+	TotalCount int64      `gorm:"not null;default:0" json:"total_count"` // Deleted rows and files
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Relationships
+	Items []RetentionRunItem `gorm:"foreignKey:RetentionRunID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// RetentionRunItem holds what a retention run deleted of one entity (of one tenant)
+type RetentionRunItem struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RetentionRunID uint      `gorm:"index;not null" json:"retention_run_id"`
+	TenantID       *uint     `gorm:"index" json:"tenant_id,omitempty"`        // Nil for log files
+	Entity         string    `gorm:"type:varchar(30);not null" json:"entity"` // timetables, friend_requests, custom_hours, log_files
+	RetentionDays  int       `gorm:"not null" json:"retention_days"`
+	Cutoff         time.Time `gorm:"not null" json:"cutoff"` // Data older than this was deleted
+	Count          int64     `gorm:"not null" json:"count"`
+	Files          *string   `gorm:"type:text" json:"files,omitempty"` // Deleted log files, one per line
+}
+
+// Retention run triggers
+const (
+	RetentionTriggerCron   = "cron"   // Scheduled run of the retention jobs
+	RetentionTriggerManual = "manual" // Started by an admin
+)
+
+// Retention run status values
+const (
+	RetentionRunStatusSuccess = "success"
+	RetentionRunStatusFailed  = "failed"
+)
+
+// Retention entities
+const (
+	RetentionEntityTimetables     = "timetables"
+	RetentionEntityFriendRequests = "friend_requests"
+	RetentionEntityCustomHours    = "custom_hours"
+	RetentionEntityLogFiles       = "log_files"
+)
+
+// ImportRunZenturieResult holds the import counts of one zenturie within an import run
+type ImportRunZenturieResult struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	ImportRunID uint   `gorm:"index;not null" json:"import_run_id"`
+	Zenturie    string `gorm:"not null;size:50" json:"zenturie"`
+	Created     int    `gorm:"not null;default:0" json:"created"`
+	Updated     int    `gorm:"not null;default:0" json:"updated"`
+	Unchanged   int    `gorm:"not null;default:0" json:"unchanged"`
+	Cancelled   int    `gorm:"not null;default:0" json:"cancelled"`
+	Errors      int    `gorm:"not null;default:0" json:"errors"`
+}
+
+// ImportRunFileResult holds the fetch/parse outcome of one source file within an import run
+type ImportRunFileResult struct {
+	ID           uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ImportRunID  uint    `gorm:"index;not null" json:"import_run_id"`
+	Zenturie     string  `gorm:"not null;size:50" json:"zenturie"`
+	SourceFile   string  `gorm:"not null;size:255" json:"source_file"`
+	URL          string  `gorm:"size:1000" json:"url,omitempty"`
+	Status       string  `gorm:"type:varchar(20);not null" json:"status"` // downloaded, unchanged, failed, quarantined
+	EventsParsed int     `gorm:"not null;default:0" json:"events_parsed"`
+	Error        *string `gorm:"type:text" json:"error,omitempty"`
+}
+
+// Import file result status values
+const (
+	ImportFileDownloaded  = "downloaded"
+	ImportFileUnchanged   = "unchanged"
+	ImportFileFailed      = "failed"
+	ImportFileQuarantined = "quarantined" // Held back by an import sanity guard
+)
+
+// ImportQuarantine is a source file held back by an import sanity guard
+// The parsed events are kept until an admin approves (imports) or rejects the file.
+type ImportQuarantine struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint       `gorm:"not null;index:idx_tenant_quarantine_status" json:"tenant_id"`
+	ImportRunID    *uint      `gorm:"index" json:"import_run_id,omitempty"`
+	Zenturie       string     `gorm:"not null;size:50" json:"zenturie"`
+	SourceFile     string     `gorm:"not null;size:255" json:"source_file"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'pending';index:idx_tenant_quarantine_status" json:"status"` // pending, approved, rejected, superseded
+	Reasons        string     `gorm:"type:text;not null" json:"reasons"`                                                            // One violated guard per line
+	PreviousEvents int        `gorm:"not null;default:0" json:"previous_events"`                                                    // Upcoming active events of the file before the import
+	NewEvents      int        `gorm:"not null;default:0" json:"new_events"`                                                         // Upcoming events in the quarantined file
+	MovedEvents    int        `gorm:"not null;default:0" json:"moved_events"`
+	ContentHash    string     `gorm:"size:64;not null" json:"content_hash"` // SHA-256 of the parsed events, the same file is quarantined once
+	Events         string     `gorm:"type:text;not null" json:"-"`          // Parsed events as JSON, imported on approval
+	ReviewedBy     *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Tenant    *Tenant    `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	ImportRun *ImportRun `gorm:"foreignKey:ImportRunID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+// Import quarantine status values
+const (
+	QuarantineStatusPending    = "pending"
+	QuarantineStatusApproved   = "approved"
+	QuarantineStatusRejected   = "rejected"
+	QuarantineStatusSuperseded = "superseded" // A newer version of the file was imported or quarantined
+)
+
+// TimetableChange records one change the importer made to a timetable event
+// Updates produce one row per changed field, creates and cancellations one row each
+type TimetableChange struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID    uint      `gorm:"not null;index:idx_tenant_zenturie_change" json:"tenant_id"`
+	ZenturienID uint      `gorm:"not null;index:idx_tenant_zenturie_change" json:"zenturien_id"`
+	ImportRunID *uint     `gorm:"index" json:"import_run_id,omitempty"`
+	TimetableID uint      `gorm:"index;not null" json:"timetable_id"`
+	UID         string    `gorm:"not null" json:"uid"`
+	ChangeType  string    `gorm:"type:varchar(20);not null" json:"change_type"` // created, updated, cancelled
+	Field       *string   `gorm:"size:50" json:"field,omitempty"`               // e.g. "room", "start_time" (updates only)
+	OldValue    *string   `gorm:"type:text" json:"old_value,omitempty"`
+	NewValue    *string   `gorm:"type:text" json:"new_value,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index:idx_tenant_zenturie_change" json:"created_at"`
+
+	// Relationships
+	Tenant    *Tenant    `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+	Zenturie  *Zenturie  `gorm:"foreignKey:ZenturienID;constraint:OnDelete:CASCADE" json:"-"`
+	ImportRun *ImportRun `gorm:"foreignKey:ImportRunID;constraint:OnDelete:SET NULL" json:"-"`
+	Timetable *Timetable `gorm:"foreignKey:TimetableID;constraint:OnDelete:CASCADE" json:"timetable,omitempty"`
+}
+
+// Timetable change types
+const (
+	TimetableChangeCreated   = "created"
+	TimetableChangeUpdated   = "updated"
+	TimetableChangeCancelled = "cancelled"
+)
+
 // CustomHour represents a user-defined appointment
 type CustomHour struct {
 	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -159,6 +618,30 @@ type Exam struct {
 	Room   *Room   `gorm:"foreignKey:RoomID;constraint:OnDelete:SET NULL" json:"room,omitempty"`
 }
 
+// CourseSelection personalizes the timetable of a user: enroll adds a course or event series of another
+// zenturie (e.g. an elective), drop hides one of the own zenturie the user doesn't attend.
+// Events are matched by course, or by title for events without course (electives are imported as "WP").
+type CourseSelection struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint      `gorm:"index;not null" json:"user_id"`
+	ZenturienID uint      `gorm:"index;not null" json:"zenturien_id"` // Zenturie whose events are selected
+	Action      string    `gorm:"type:varchar(10);not null;check:action IN ('enroll', 'drop')" json:"action"`
+	CourseID    *uint     `gorm:"index" json:"course_id,omitempty"`
+	Summary     *string   `gorm:"size:500" json:"summary,omitempty"` // Event series, set instead of CourseID
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	User     *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Zenturie *Zenturie `gorm:"foreignKey:ZenturienID;constraint:OnDelete:CASCADE" json:"zenturie,omitempty"`
+	Course   *Course   `gorm:"foreignKey:CourseID;constraint:OnDelete:CASCADE" json:"course,omitempty"`
+}
+
+// Course selection actions
+const (
+	CourseSelectionEnroll = "enroll"
+	CourseSelectionDrop   = "drop"
+)
+
 // Friend represents a friendship relationship (v1 API - deprecated, kept for backwards compatibility)
 type Friend struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
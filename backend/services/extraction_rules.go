@@ -0,0 +1,247 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+)
+
+// DefaultExtractionRules returns the NAK rule set, used for tenants without own rules
+//
+//	Summary "V I231 Algorithmen (Prof. Dr. Müller)" -> course type, course code, professor
+//	Description "Dozent: ...\nRaum: ..."              -> professor (if not in summary), room
+//	Location                                          -> room (if not in description)
+func DefaultExtractionRules() []models.ExtractionRule {
+	return []models.ExtractionRule{
+		{Field: models.ExtractFieldCourseType, Source: models.ExtractSourceSummary, Kind: models.ExtractKindRegex, Pattern: `^\s*(\S+)`, Group: 1, Position: 10, IsActive: true},
+		{Field: models.ExtractFieldCourseCode, Source: models.ExtractSourceSummary, Kind: models.ExtractKindRegex, Pattern: `^\s*\S+\s+(\S+)`, Group: 1, Position: 20, IsActive: true},
+		{Field: models.ExtractFieldProfessor, Source: models.ExtractSourceSummary, Kind: models.ExtractKindRegex, Pattern: `\(([^)]*)\)`, Group: 1, Position: 30, IsActive: true},
+		{Field: models.ExtractFieldProfessor, Source: models.ExtractSourceDescription, Kind: models.ExtractKindLabel, Pattern: "Dozent:", Position: 40, IsActive: true},
+		{Field: models.ExtractFieldRoom, Source: models.ExtractSourceDescription, Kind: models.ExtractKindLabel, Pattern: "Raum:", Position: 50, IsActive: true},
+		{Field: models.ExtractFieldRoom, Source: models.ExtractSourceLocation, Kind: models.ExtractKindValue, Position: 60, IsActive: true},
+	}
+}
+
+// ExtractionRuleSet is a validated, ordered list of extraction rules
+type ExtractionRuleSet struct {
+	rules []compiledRule
+}
+
+// compiledRule is an extraction rule with its compiled regular expression (regex rules only)
+type compiledRule struct {
+	models.ExtractionRule
+	re *regexp.Regexp
+}
+
+// CompileExtractionRules validates rules and compiles them in Position order, inactive rules are skipped
+func CompileExtractionRules(rules []models.ExtractionRule) (*ExtractionRuleSet, error) {
+	set := &ExtractionRuleSet{}
+	for i := range rules {
+		rule := rules[i]
+		re, err := validateExtractionRule(&rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		if rule.IsActive {
+			set.rules = append(set.rules, compiledRule{ExtractionRule: rule, re: re})
+		}
+	}
+
+	sort.SliceStable(set.rules, func(i, j int) bool {
+		return set.rules[i].Position < set.rules[j].Position
+	})
+
+	return set, nil
+}
+
+// validateExtractionRule checks field, source, kind and pattern of a rule
+// Returns the compiled expression of regex rules
+func validateExtractionRule(rule *models.ExtractionRule) (*regexp.Regexp, error) {
+	switch rule.Field {
+	case models.ExtractFieldProfessor, models.ExtractFieldRoom, models.ExtractFieldCourseType,
+		models.ExtractFieldCourseCode, models.ExtractFieldOnlineLink, models.ExtractFieldNotes:
+	default:
+		return nil, fmt.Errorf("unknown field %q", rule.Field)
+	}
+
+	switch rule.Source {
+	case models.ExtractSourceSummary, models.ExtractSourceDescription, models.ExtractSourceLocation:
+	default:
+		return nil, fmt.Errorf("unknown source %q", rule.Source)
+	}
+
+	switch rule.Kind {
+	case models.ExtractKindValue:
+		return nil, nil
+	case models.ExtractKindLabel:
+		if strings.TrimSpace(rule.Pattern) == "" {
+			return nil, fmt.Errorf("label rules need a pattern")
+		}
+		return nil, nil
+	case models.ExtractKindRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		if rule.Group < 0 || rule.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("capture group %d does not exist in %q", rule.Group, rule.Pattern)
+		}
+		return re, nil
+	default:
+		return nil, fmt.Errorf("unknown kind %q", rule.Kind)
+	}
+}
+
+// LoadExtractionRules loads the active rules of a tenant, or the default rules if it has none stored
+// A tenant that deactivated all its rules extracts nothing (same as GetExtractionRules isDefault)
+func LoadExtractionRules(db *gorm.DB, tenantID uint) (*ExtractionRuleSet, error) {
+	var rules []models.ExtractionRule
+	if err := db.Where("tenant_id = ?", tenantID).
+		Order("position ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load extraction rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		rules = DefaultExtractionRules()
+	}
+
+	return CompileExtractionRules(rules)
+}
+
+// GetExtractionRules returns the stored rules of a tenant (active and inactive)
+// isDefault is true if the tenant has no rules and the default rule set applies
+func GetExtractionRules(tenantID uint) (rules []models.ExtractionRule, isDefault bool, err error) {
+	if err := config.DB.Where("tenant_id = ?", tenantID).Order("position ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to load extraction rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		return DefaultExtractionRules(), true, nil
+	}
+	return rules, false, nil
+}
+
+// SaveExtractionRules replaces the rule set of a tenant
+// An empty list deletes the tenant's rules, so the default rule set applies again
+func SaveExtractionRules(tenantID uint, rules []models.ExtractionRule) error {
+	if _, err := CompileExtractionRules(rules); err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&models.ExtractionRule{}).Error; err != nil {
+			return fmt.Errorf("failed to delete extraction rules: %w", err)
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+
+		for i := range rules {
+			rules[i].ID = 0
+			rules[i].TenantID = tenantID
+		}
+		if err := tx.Create(&rules).Error; err != nil {
+			return fmt.Errorf("failed to save extraction rules: %w", err)
+		}
+		return nil
+	})
+}
+
+// Apply fills the extracted fields of event that are still empty
+// Values provided by the source (e.g. JSON feeds) are kept, the first matching rule per field wins
+func (s *ExtractionRuleSet) Apply(event *TimetableEvent) {
+	for _, rule := range s.rules {
+		target := extractedField(event, rule.Field)
+		if target == nil || *target != "" {
+			continue
+		}
+
+		if value := rule.extract(ruleSource(event, rule.Source)); value != "" {
+			*target = value
+		}
+	}
+}
+
+// extract returns the value the rule captures from input, or ""
+func (r compiledRule) extract(input string) string {
+	if input == "" {
+		return ""
+	}
+
+	var value string
+	switch r.Kind {
+	case models.ExtractKindValue:
+		value = input
+	case models.ExtractKindLabel:
+		value = extractLabel(input, r.Pattern)
+	case models.ExtractKindRegex:
+		match := r.re.FindStringSubmatch(input)
+		if match == nil {
+			return ""
+		}
+		value = match[r.Group]
+	}
+
+	// "-" means "not set" in the NAK feeds
+	value = strings.TrimSpace(value)
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+// extractLabel returns the text after label up to the next newline
+// ICS descriptions may contain literal "\n" (backslash + n) or actual newline characters
+func extractLabel(input, label string) string {
+	idx := strings.Index(input, label)
+	if idx == -1 {
+		return ""
+	}
+
+	rest := input[idx+len(label):]
+	if end := strings.Index(rest, "\\n"); end != -1 {
+		rest = rest[:end]
+	}
+	if end := strings.Index(rest, "\n"); end != -1 {
+		rest = rest[:end]
+	}
+	return rest
+}
+
+// extractedField returns the event field a rule fills
+func extractedField(event *TimetableEvent, field string) *string {
+	switch field {
+	case models.ExtractFieldProfessor:
+		return &event.Professor
+	case models.ExtractFieldRoom:
+		return &event.Room
+	case models.ExtractFieldCourseType:
+		return &event.CourseType
+	case models.ExtractFieldCourseCode:
+		return &event.CourseCode
+	case models.ExtractFieldOnlineLink:
+		return &event.OnlineLink
+	case models.ExtractFieldNotes:
+		return &event.Notes
+	}
+	return nil
+}
+
+// ruleSource returns the event property a rule reads
+func ruleSource(event *TimetableEvent, source string) string {
+	switch source {
+	case models.ExtractSourceSummary:
+		return event.Summary
+	case models.ExtractSourceDescription:
+		return event.Description
+	case models.ExtractSourceLocation:
+		return event.Location
+	}
+	return ""
+}
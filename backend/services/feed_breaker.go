@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/utils"
+	"gorm.io/gorm/clause"
+)
+
+// Circuit breaker states of a feed URL
+const (
+	BreakerStateClosed   = "closed"    // Failing, but below the threshold
+	BreakerStateOpen     = "open"      // Paused until OpenUntil
+	BreakerStateHalfOpen = "half_open" // Pause is over, the next run tries once
+)
+
+// FeedBreakerStatus is the circuit breaker state of a failing feed URL
+type FeedBreakerStatus struct {
+	Zenturie            string     `json:"zenturie"`
+	SourceFile          string     `json:"source_file"`
+	URL                 string     `json:"url,omitempty"` // Admins only
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"` // Admins only
+	LastFailureAt       time.Time  `json:"last_failure_at"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+// loadFeedBreakers loads the circuit breakers of all failing feed URLs of a tenant, keyed by URL
+func loadFeedBreakers(tenantID uint) (map[string]models.FeedCircuitBreaker, error) {
+	var breakers []models.FeedCircuitBreaker
+	if err := config.DB.Where("tenant_id = ?", tenantID).Find(&breakers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load feed circuit breakers: %w", err)
+	}
+
+	result := make(map[string]models.FeedCircuitBreaker, len(breakers))
+	for _, breaker := range breakers {
+		result[breaker.URL] = breaker
+	}
+	return result, nil
+}
+
+// breakerOpen reports whether a feed URL is paused
+func breakerOpen(breaker *models.FeedCircuitBreaker, now time.Time) bool {
+	return breaker.OpenUntil != nil && now.Before(*breaker.OpenUntil)
+}
+
+// breakerState returns the state of a circuit breaker
+func breakerState(breaker *models.FeedCircuitBreaker, now time.Time) string {
+	switch {
+	case breaker.OpenUntil == nil:
+		return BreakerStateClosed
+	case now.Before(*breaker.OpenUntil):
+		return BreakerStateOpen
+	default:
+		return BreakerStateHalfOpen
+	}
+}
+
+// breakerOpenResult builds the result of a job skipped because its URL is paused
+// Reported like a failed download, so the events of the file are kept.
+func breakerOpenResult(job fetchJob, breaker *models.FeedCircuitBreaker) fetchResult {
+	log.Printf("  Skipping %s, circuit breaker open until %s", job.URL, breaker.OpenUntil.Format(time.RFC3339))
+	return fetchResult{
+		Outcome: fetchBreakerOpen,
+		Data: ICSData{
+			Zenturie:   job.Zenturie,
+			SourceFile: job.SourceFile,
+			URL:        job.URL,
+			Error: fmt.Sprintf("circuit breaker open until %s after %d failed runs: %s",
+				breaker.OpenUntil.Format(time.RFC3339), breaker.ConsecutiveFailures, breaker.LastError),
+		},
+	}
+}
+
+// updateFeedBreakers counts the failed downloads of a run per URL and opens the circuit breaker of
+// URLs that failed ICSBreakerThreshold runs in a row. Any response (also 404) closes the breaker.
+// The team is notified once when a breaker opens, not when it opens again after a failed retry.
+func updateFeedBreakers(tenant *models.Tenant, jobs []fetchJob, results []fetchResult, breakers map[string]models.FeedCircuitBreaker) {
+	now := time.Now().UTC()
+	threshold := config.AppConfig.ICSBreakerThreshold
+	cooldown := time.Duration(config.AppConfig.ICSBreakerCooldown) * time.Minute
+
+	var opened []models.FeedCircuitBreaker
+	for i, job := range jobs {
+		breaker, exists := breakers[job.URL]
+
+		switch results[i].Outcome {
+		case fetchOK, fetchUnchanged, fetchNotFound:
+			if !exists {
+				continue
+			}
+			if err := config.DB.Delete(&breaker).Error; err != nil {
+				log.Printf("WARNING: Failed to reset circuit breaker of %s: %v", job.URL, err)
+				continue
+			}
+			if breaker.OpenedAt != nil {
+				log.Printf("Circuit breaker of %s closed, feed is reachable again", job.URL)
+			}
+
+		case fetchFailed:
+			breaker.TenantID = tenant.ID
+			breaker.URL = job.URL
+			breaker.Zenturie = job.Zenturie
+			breaker.SourceFile = job.SourceFile
+			breaker.ConsecutiveFailures++
+			breaker.LastError = results[i].Data.Error
+			breaker.LastFailureAt = now
+
+			if breaker.ConsecutiveFailures >= threshold {
+				openUntil := now.Add(cooldown)
+				breaker.OpenUntil = &openUntil
+				if breaker.OpenedAt == nil {
+					breaker.OpenedAt = &now
+					opened = append(opened, breaker)
+				}
+				log.Printf("WARNING: Circuit breaker of %s open until %s (%d failed runs)",
+					job.URL, openUntil.Format(time.RFC3339), breaker.ConsecutiveFailures)
+			}
+
+			err := config.DB.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "tenant_id"}, {Name: "url"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"zenturie", "source_file", "consecutive_failures", "last_error",
+					"last_failure_at", "opened_at", "open_until", "updated_at",
+				}),
+			}).Create(&breaker).Error
+			if err != nil {
+				log.Printf("WARNING: Failed to save circuit breaker of %s: %v", job.URL, err)
+			}
+		}
+	}
+
+	if len(opened) > 0 {
+		notifyOpenedBreakers(tenant, opened)
+	}
+}
+
+// notifyOpenedBreakers emails the team about feed URLs that were just paused
+func notifyOpenedBreakers(tenant *models.Tenant, opened []models.FeedCircuitBreaker) {
+	lines := make([]string, len(opened))
+	for i, breaker := range opened {
+		lines[i] = fmt.Sprintf("%s/%s (%s): %s", breaker.Zenturie, breaker.SourceFile, breaker.URL, breaker.LastError)
+	}
+
+	log.Printf("%d feed URL(s) of tenant %s paused, sending alert", len(opened), tenant.Slug)
+	if err := utils.NewEmailService().SendFeedBreakerAlert(tenant.Name, lines, config.AppConfig.ICSBreakerThreshold, config.AppConfig.ICSBreakerCooldown); err != nil {
+		log.Printf("WARNING: Failed to send circuit breaker alert: %v", err)
+	}
+}
+
+// GetFeedBreakers returns the circuit breakers of all failing feed URLs of a tenant
+func GetFeedBreakers(tenantID uint) []FeedBreakerStatus {
+	var breakers []models.FeedCircuitBreaker
+	if err := config.DB.Where("tenant_id = ?", tenantID).Order("zenturie, source_file").Find(&breakers).Error; err != nil {
+		log.Printf("WARNING: Failed to load feed circuit breakers: %v", err)
+	}
+
+	now := time.Now()
+	statuses := make([]FeedBreakerStatus, len(breakers))
+	for i := range breakers {
+		breaker := &breakers[i]
+		statuses[i] = FeedBreakerStatus{
+			Zenturie:            breaker.Zenturie,
+			SourceFile:          breaker.SourceFile,
+			URL:                 breaker.URL,
+			State:               breakerState(breaker, now),
+			ConsecutiveFailures: breaker.ConsecutiveFailures,
+			LastError:           breaker.LastError,
+			LastFailureAt:       breaker.LastFailureAt,
+			OpenedAt:            breaker.OpenedAt,
+			OpenUntil:           breaker.OpenUntil,
+		}
+	}
+	return statuses
+}
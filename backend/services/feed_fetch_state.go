@@ -0,0 +1,93 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm/clause"
+)
+
+// hashContent returns the hex encoded SHA-256 hash of a raw feed body
+func hashContent(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadFeedFetchStates loads the caching metadata of all feed URLs of a tenant, keyed by URL
+func loadFeedFetchStates(tenantID uint) (map[string]models.FeedFetchState, error) {
+	var states []models.FeedFetchState
+	if err := config.DB.Where("tenant_id = ?", tenantID).Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("failed to load feed fetch states: %w", err)
+	}
+
+	result := make(map[string]models.FeedFetchState, len(states))
+	for _, state := range states {
+		// Recurring events are only expanded up to now+ICS_RECURRENCE_FUTURE_DAYS, so a file with
+		// RRULE/RDATE is parsed again once the window moved on, even if it did not change
+		if state.RecurrenceExpandedAt != nil && time.Since(*state.RecurrenceExpandedAt) >= recurrenceReparseInterval {
+			continue
+		}
+		result[state.URL] = state
+	}
+	return result, nil
+}
+
+// SaveFeedFetchStates persists the caching metadata of fetched files
+// Must only be called after the import succeeded, otherwise changed files would be skipped on the next run
+func SaveFeedFetchStates(tenantID uint, icsData []ICSData) error {
+	now := time.Now()
+
+	for _, data := range icsData {
+		if data.URL == "" || data.ContentHash == "" || data.Error != "" {
+			continue
+		}
+
+		state := models.FeedFetchState{
+			TenantID:      tenantID,
+			URL:           data.URL,
+			ContentHash:   data.ContentHash,
+			LastFetchedAt: now,
+			LastChangedAt: now,
+		}
+		if data.ETag != "" {
+			state.ETag = &data.ETag
+		}
+		if data.LastModified != "" {
+			state.LastModified = &data.LastModified
+		}
+		if containsRecurrence(data.Content) {
+			state.RecurrenceExpandedAt = &now
+		}
+
+		// Unchanged files only refresh the fetch timestamp (and validators if the server sent new ones)
+		updateColumns := []string{"content_hash", "etag", "last_modified", "last_fetched_at", "last_changed_at", "recurrence_expanded_at"}
+		if data.Unchanged {
+			updateColumns = []string{"last_fetched_at"}
+			if data.ETag != "" || data.LastModified != "" {
+				updateColumns = append(updateColumns, "etag", "last_modified")
+			}
+		}
+
+		err := config.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "url"}},
+			DoUpdates: clause.AssignmentColumns(updateColumns),
+		}).Create(&state).Error
+		if err != nil {
+			return fmt.Errorf("failed to save fetch state for %s: %w", data.URL, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteFeedFetchState forgets the caching metadata of a feed URL, its next fetch is imported in any case
+func deleteFeedFetchState(tenantID uint, url string) error {
+	if err := config.DB.Where("tenant_id = ? AND url = ?", tenantID, url).Delete(&models.FeedFetchState{}).Error; err != nil {
+		return fmt.Errorf("failed to delete fetch state for %s: %w", url, err)
+	}
+	return nil
+}
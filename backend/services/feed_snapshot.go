@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSnapshotNotFound is returned for unknown feed snapshots
+var ErrSnapshotNotFound = errors.New("feed snapshot not found")
+
+// SaveFeedSnapshots archives the content of fetched files
+// Only ICS content is archived (pre-parsed events of the json adapter are not), a file is
+// stored again only when its content differs from its previous snapshot.
+func SaveFeedSnapshots(tenantID uint, runID *uint, icsData []ICSData) error {
+	now := time.Now().UTC()
+	saved := 0
+
+	for _, data := range icsData {
+		if data.Content == "" || data.Error != "" || data.Unchanged {
+			continue
+		}
+
+		content := []byte(data.Content)
+		hash := hashContent(content)
+
+		var latest models.FeedSnapshot
+		err := config.DB.Where("tenant_id = ? AND zenturie = ? AND source_file = ?", tenantID, data.Zenturie, data.SourceFile).
+			Order("fetched_at DESC").
+			First(&latest).Error
+		if err == nil && latest.ContentHash == hash {
+			continue
+		}
+
+		compressed, err := compressSnapshot(content)
+		if err != nil {
+			return fmt.Errorf("failed to compress %s: %w", data.SourceFile, err)
+		}
+
+		err = config.DB.Transaction(func(tx *gorm.DB) error {
+			// The same payload may already be archived for another file or an earlier version
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.FeedSnapshotContent{
+				ContentHash:    hash,
+				Data:           compressed,
+				Size:           len(content),
+				CompressedSize: len(compressed),
+			}).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&models.FeedSnapshot{
+				TenantID:    tenantID,
+				Zenturie:    data.Zenturie,
+				SourceFile:  data.SourceFile,
+				URL:         data.URL,
+				ContentHash: hash,
+				ImportRunID: runID,
+				FetchedAt:   now,
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot of %s: %w", data.SourceFile, err)
+		}
+		saved++
+	}
+
+	if saved > 0 {
+		log.Printf("Archived %d feed snapshot(s)", saved)
+	}
+	return nil
+}
+
+// LoadFeedSnapshot loads a snapshot with its decompressed content
+func LoadFeedSnapshot(id uint) (*models.FeedSnapshot, string, error) {
+	var snapshot models.FeedSnapshot
+	if err := config.DB.Preload("Content").First(&snapshot, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrSnapshotNotFound
+		}
+		return nil, "", fmt.Errorf("failed to load feed snapshot: %w", err)
+	}
+	if snapshot.Content == nil {
+		return nil, "", fmt.Errorf("content of feed snapshot %d is missing", snapshot.ID)
+	}
+
+	content, err := decompressSnapshot(snapshot.Content.Data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decompress feed snapshot %d: %w", snapshot.ID, err)
+	}
+	return &snapshot, string(content), nil
+}
+
+// ReplayFeedSnapshot parses an archived file again and imports it as if it had just been fetched
+// Events of the file that are not in the snapshot are cancelled, a snapshot without events
+// cancels all of them. Import guards do not apply. The fetch state of the file's URL is reset,
+// so the next scheduled run replaces the replayed events with the live feed again.
+// With dryRun the changes are computed without writing them (e.g. to test parser changes).
+func ReplayFeedSnapshot(id uint, dryRun bool) (*TenantImportResult, error) {
+	snapshot, content, err := LoadFeedSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant models.Tenant
+	if err := config.DB.First(&tenant, snapshot.TenantID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tenant of feed snapshot %d: %w", snapshot.ID, err)
+	}
+
+	log.Printf("Replaying feed snapshot %d (%s of zenturie %s, fetched %s)",
+		snapshot.ID, snapshot.SourceFile, snapshot.Zenturie, snapshot.FetchedAt.Format(time.RFC3339))
+
+	events, err := ParseICSFiles([]ICSData{{
+		Zenturie:   snapshot.Zenturie,
+		SourceFile: snapshot.SourceFile,
+		Content:    content,
+	}}, TenantLocation(&tenant))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := runFileImport(tenant.ID, models.ImportTriggerReplay, snapshot.Zenturie, snapshot.SourceFile, events[snapshot.Zenturie], dryRun)
+	if err != nil || dryRun {
+		return result, err
+	}
+
+	// The live feed is unchanged since the last fetch, forget its fetch state so the next run imports it again
+	if snapshot.URL != "" {
+		if err := deleteFeedFetchState(tenant.ID, snapshot.URL); err != nil {
+			log.Printf("WARNING: Failed to reset fetch state of %s after replay: %v", snapshot.URL, err)
+		}
+	}
+	return result, nil
+}
+
+// compressSnapshot gzips a feed payload
+func compressSnapshot(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressSnapshot reverses compressSnapshot
+func decompressSnapshot(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultFeedURLPattern is the NAK file layout: <base>/<zenturie>_<semester>.ics
+const DefaultFeedURLPattern = "{base}/{zenturie}_{semester}.ics"
+
+// ErrNoFeedSource is returned when a tenant has no feed source configured
+var ErrNoFeedSource = errors.New("no feed source configured")
+
+// GetFeedSource loads the feed source of a tenant
+func GetFeedSource(tenantID uint) (*models.FeedSource, error) {
+	var source models.FeedSource
+	if err := config.DB.Where("tenant_id = ?", tenantID).First(&source).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoFeedSource
+		}
+		return nil, fmt.Errorf("failed to load feed source: %w", err)
+	}
+	return &source, nil
+}
+
+// SaveFeedSource creates or updates the feed source of a tenant
+func SaveFeedSource(source *models.FeedSource) error {
+	if source.URLPattern == "" {
+		source.URLPattern = DefaultFeedURLPattern
+	}
+	if source.MaxSemester <= 0 {
+		source.MaxSemester = 7
+	}
+	if source.Adapter == "" {
+		source.Adapter = AdapterICSURL
+	}
+	if source.Encoding == "" {
+		source.Encoding = EncodingLatin1
+	}
+
+	if err := config.DB.Save(source).Error; err != nil {
+		return fmt.Errorf("failed to save feed source: %w", err)
+	}
+
+	log.Printf("Saved feed source for tenant %d: adapter %s", source.TenantID, source.Adapter)
+
+	// Semester files known from the previous URL may not exist in the new feed
+	if err := ResetSemesterDiscovery(source.TenantID); err != nil {
+		log.Printf("WARNING: Failed to reset semester discovery for tenant %d: %v", source.TenantID, err)
+	}
+	return nil
+}
+
+// SaveFeedUpload stores an uploaded ICS file for the "upload" adapter, replacing a previous file with the same name
+func SaveFeedUpload(tenantID uint, fileName string, content []byte, encoding string, uploadedBy *uint) (*models.FeedUpload, error) {
+	zenturie, _, ok := parseSourceFileName(fileName)
+	if !ok {
+		return nil, fmt.Errorf("file name must follow <zenturie>_<semester>.ics")
+	}
+
+	utf8Content, err := decodeFeedContent(content, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert encoding: %w", err)
+	}
+
+	upload := models.FeedUpload{
+		TenantID:   tenantID,
+		FileName:   fileName,
+		Zenturie:   zenturie,
+		Content:    string(utf8Content),
+		Size:       len(content),
+		UploadedBy: uploadedBy,
+	}
+
+	err = config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "file_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"zenturie", "content", "size", "uploaded_by", "updated_at"}),
+	}).Create(&upload).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save uploaded file: %w", err)
+	}
+
+	log.Printf("Stored uploaded ICS file %s for tenant %d (%d bytes)", fileName, tenantID, len(content))
+	return &upload, nil
+}
@@ -2,11 +2,17 @@ package services
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	ical "github.com/emersion/go-ical"
@@ -14,21 +20,55 @@ import (
 	"github.com/nora-nak/backend/models"
 	"golang.org/x/text/encoding/charmap"
 	"golang.org/x/text/transform"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// ICSData represents fetched ICS file data
+// ICSData represents fetched timetable data of one source file
+// ICS adapters set Content, adapters with their own format (JSON) set pre-parsed Events instead
 type ICSData struct {
-	Zenturie string
-	Content  string
+	Zenturie   string
+	SourceFile string // File name within the feed, e.g. "I24c_3.ics"
+	Content    string
+	Events     []TimetableEvent
+
+	InvalidEvents int // Events the adapter could not use, counted as import errors
+
+	// HTTP caching metadata, persisted after a successful import
+	URL          string
+	ETag         string
+	LastModified string
+	ContentHash  string
+	Unchanged    bool   // File is unchanged since the last import (304 or same hash), Content is empty
+	Error        string // Fetching failed, Content is empty (kept for the import run report)
 }
 
 // ImportStatistics represents import statistics
 type ImportStatistics struct {
-	FilesDownloaded int
-	EventsCreated   int
-	EventsUpdated   int
-	EventsUnchanged int
-	Errors          int
+	FilesDownloaded       int
+	FilesSkippedUnchanged int
+	FilesFailed           int
+	EventsCreated         int
+	EventsUpdated         int
+	EventsUnchanged       int
+	EventsCancelled       int
+	Errors                int
+	Zenturien             []ZenturieStatistics
+	Quarantined           []QuarantinedFile
+	Preview               *ImportPreview // Dry-run imports only
+}
+
+// ZenturieStatistics represents the import statistics of one zenturie
+type ZenturieStatistics struct {
+	Zenturie  string
+	Created   int
+	Updated   int
+	Unchanged int
+	Cancelled int
+	Errors    int
+
+	Quarantined []QuarantinedFile
+	FailedFiles []string // Source files that were rolled back or could not be imported at all
 }
 
 // TimetableEvent represents a parsed event
@@ -42,115 +82,443 @@ type TimetableEvent struct {
 	Professor   string
 	CourseCode  string
 	CourseType  string
+	Room        string // Room location, e.g. "A103, EDV-A102"
+	OnlineLink  string
+	Notes       string
+	SourceFile  string
+	IsAllDay    bool // VALUE=DATE event, StartTime/EndTime are local midnights
 }
 
-// FetchICSFiles fetches ICS files from external source
-// Fetches all zenturien from database and tries all semesters (1-7)
-func FetchICSFiles() ([]ICSData, error) {
-	// Get all zenturien from database
+// fetchOutcome classifies the result of a single feed download
+type fetchOutcome int
+
+const (
+	fetchOK fetchOutcome = iota
+	fetchUnchanged
+	fetchNotFound
+	fetchFailed
+	fetchBreakerOpen // Not requested, the circuit breaker of the URL is open
+)
+
+// fetchJob is one zenturie/semester file to download
+type fetchJob struct {
+	Zenturie   string
+	ZenturieID uint
+	Semester   int
+	URL        string
+	SourceFile string
+	Encoding   string
+}
+
+// fetchResult is the outcome of a fetchJob, Data is set for all outcomes except fetchNotFound
+type fetchResult struct {
+	Outcome   fetchOutcome
+	Data      ICSData
+	Retryable bool // Failure may be transient (network error, 5xx, 429)
+}
+
+// FetchICSFiles fetches ICS files for a single tenant from its feed source
+// Fetches the tenant's zenturien from database and downloads their known semester files.
+// All semesters (1..MaxSemester) are probed for new zenturien and every ICSDiscoveryHours.
+// Transient failures are retried with backoff, URLs with an open circuit breaker are skipped.
+// Downloads run concurrently (config.AppConfig.ICSFetchWorkers, capped per host), the
+// result order is the same as a sequential run: by zenturie, then by semester.
+func FetchICSFiles(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, opts FetchOptions) ([]ICSData, error) {
+	// Get all zenturien of this tenant (or the requested one) from database
+	query := config.DB.Where("tenant_id = ?", tenant.ID)
+	if opts.Zenturie != "" {
+		query = query.Where("LOWER(name) = LOWER(?)", opts.Zenturie)
+	}
 	var zenturien []models.Zenturie
-	if err := config.DB.Find(&zenturien).Error; err != nil {
-		log.Printf("ERROR fetching zenturien from database: %v", err)
+	if err := query.Find(&zenturien).Error; err != nil {
+		log.Printf("ERROR fetching zenturien for tenant %s from database: %v", tenant.Slug, err)
 		return nil, err
 	}
 
 	if len(zenturien) == 0 {
-		log.Println("WARNING: No zenturien found in database")
+		log.Printf("WARNING: No zenturien found in database for tenant %s", tenant.Slug)
+		return []ICSData{}, nil
+	}
+
+	log.Printf("Found %d zenturien in database for tenant %s", len(zenturien), tenant.Slug)
+
+	maxSemester := source.MaxSemester
+	if maxSemester <= 0 {
+		maxSemester = 7
+	}
+
+	// Load caching metadata of previous fetches
+	fetchStates, err := loadFeedFetchStates(tenant.ID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load feed fetch states, fetching unconditionally: %v", err)
+		fetchStates = map[string]models.FeedFetchState{}
+	}
+
+	// Failing URLs, open breakers are skipped
+	breakers, err := loadFeedBreakers(tenant.ID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load feed circuit breakers, fetching all URLs: %v", err)
+		breakers = map[string]models.FeedCircuitBreaker{}
+	}
+
+	// Semester files found by earlier runs
+	probeAll := false
+	knownSemesters, err := loadKnownSemesters(tenant.ID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load known semesters, probing all semesters: %v", err)
+		probeAll = true
+	}
+
+	// Build job list in sequential order (zenturie, then semester)
+	now := time.Now()
+	var jobs []fetchJob
+	discovered := make(map[uint]bool)
+	for i := range zenturien {
+		zenturie := &zenturien[i]
+		semesters := knownSemesters[zenturie.ID]
+		if probeAll || semesterDiscoveryDue(zenturie, now) {
+			discovered[zenturie.ID] = true
+			semesters = make([]int, 0, maxSemester)
+			for semester := 1; semester <= maxSemester; semester++ {
+				semesters = append(semesters, semester)
+			}
+		}
+
+		for _, semester := range semesters {
+			jobs = append(jobs, fetchJob{
+				Zenturie:   zenturie.Name,
+				ZenturieID: zenturie.ID,
+				Semester:   semester,
+				URL:        buildFeedURL(source, zenturie.Name, semester),
+				SourceFile: fmt.Sprintf("%s_%d.ics", zenturie.Name, semester),
+				Encoding:   source.Encoding,
+			})
+		}
+	}
+
+	// No known semesters and no discovery due (all semesters returned 404 recently): nothing to fetch
+	if len(jobs) == 0 {
+		log.Printf("No semester files to fetch for tenant %s until the next discovery", tenant.Slug)
 		return []ICSData{}, nil
 	}
 
-	log.Printf("Found %d zenturien in database", len(zenturien))
+	log.Printf("Fetching %d files (%d zenturien with full semester discovery)", len(jobs), len(discovered))
+	results := runFetchJobs(ctx, jobs, fetchStates, breakers)
+
+	// Scheduler stopped while fetching: discard partial results
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("fetch for tenant %s cancelled: %w", tenant.Slug, err)
+	}
+
+	saveKnownSemesters(tenant.ID, jobs, results, discovered)
+	updateFeedBreakers(tenant, jobs, results, breakers)
 
 	var icsData []ICSData
-	baseURL := config.AppConfig.ICSBaseURL
+	successCount := 0
+	unchangedCount := 0
+	errorCount := 0
+	pausedCount := 0
+
+	for _, result := range results {
+		switch result.Outcome {
+		case fetchOK:
+			successCount++
+			icsData = append(icsData, result.Data)
+		case fetchUnchanged:
+			unchangedCount++
+			icsData = append(icsData, result.Data)
+		case fetchFailed:
+			errorCount++
+			icsData = append(icsData, result.Data)
+		case fetchBreakerOpen:
+			pausedCount++
+			icsData = append(icsData, result.Data)
+		}
+	}
+
+	log.Printf("Fetch summary: %d successful, %d unchanged, %d errors, %d paused", successCount, unchangedCount, errorCount, pausedCount)
+	log.Printf("Total ICS files fetched: %d", successCount+unchangedCount)
+
+	// Return error only if downloads were attempted and ALL of them failed (404s and paused URLs are no failures)
+	if successCount+unchangedCount == 0 && errorCount > 0 {
+		return nil, fmt.Errorf("failed to fetch any ICS files for tenant %s (tried %d zenturien)", tenant.Slug, len(zenturien))
+	}
+
+	return icsData, nil
+}
+
+// runFetchJobs downloads all jobs with a bounded worker pool
+// results[i] always belongs to jobs[i]; jobs not started before ctx is cancelled are reported as failed
+func runFetchJobs(ctx context.Context, jobs []fetchJob, fetchStates map[string]models.FeedFetchState, breakers map[string]models.FeedCircuitBreaker) []fetchResult {
+	workers := config.AppConfig.ICSFetchWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	perHost := config.AppConfig.ICSFetchPerHostCap
+	if perHost <= 0 {
+		perHost = workers
+	}
+
+	// One semaphore per host, created up front so workers only read the map
+	hostSlots := make(map[string]chan struct{})
+	for _, job := range jobs {
+		host := feedHost(job.URL)
+		if _, ok := hostSlots[host]; !ok {
+			hostSlots[host] = make(chan struct{}, perHost)
+		}
+	}
 
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
-	successCount := 0
-	errorCount := 0
+	results := make([]fetchResult, len(jobs))
+	queue := make(chan int)
+	now := time.Now()
 
-	// Try each zenturie with all possible semesters (1-7)
-	for _, zenturie := range zenturien {
-		log.Printf("Processing zenturie: %s", zenturie.Name)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if breaker, ok := breakers[jobs[i].URL]; ok && breakerOpen(&breaker, now) {
+					results[i] = breakerOpenResult(jobs[i], &breaker)
+					continue
+				}
 
-		for semester := 1; semester <= 7; semester++ {
-			url := fmt.Sprintf("%s/%s_%d.ics", baseURL, zenturie.Name, semester)
-			log.Printf("  Attempting to fetch: %s", url)
+				slots := hostSlots[feedHost(jobs[i].URL)]
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					results[i] = fetchFailure(jobs[i], ctx.Err())
+					continue
+				}
 
-			resp, err := client.Get(url)
-			if err != nil {
-				log.Printf("  ERROR fetching ICS for %s semester %d: %v", zenturie.Name, semester, err)
-				errorCount++
-				continue
+				state, known := fetchStates[jobs[i].URL]
+				results[i] = fetchICSFileWithRetry(ctx, client, jobs[i], state, known)
+				<-slots
 			}
+		}()
+	}
 
-			// Check if file exists (404 means semester doesn't exist)
-			if resp.StatusCode == 404 {
-				log.Printf("  No ICS file found for %s semester %d (404)", zenturie.Name, semester)
-				resp.Body.Close()
-				continue
-			}
+	for i := range jobs {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			results[i] = fetchFailure(jobs[i], ctx.Err())
+		}
+	}
+	close(queue)
+	wg.Wait()
 
-			if resp.StatusCode != 200 {
-				log.Printf("  ERROR: Unexpected status code %d for %s semester %d", resp.StatusCode, zenturie.Name, semester)
-				resp.Body.Close()
-				errorCount++
-				continue
-			}
+	return results
+}
 
-			body, err := io.ReadAll(resp.Body)
-			resp.Body.Close()
+// fetchICSFileWithRetry downloads a feed file, retrying transient failures with exponential backoff
+// The host slot stays taken while waiting, which also slows down requests to a struggling host.
+func fetchICSFileWithRetry(ctx context.Context, client *http.Client, job fetchJob, state models.FeedFetchState, known bool) fetchResult {
+	retries := config.AppConfig.ICSFetchRetries
+	delay := time.Duration(config.AppConfig.ICSFetchRetryDelay) * time.Second
 
-			if err != nil {
-				log.Printf("  ERROR reading ICS for %s semester %d: %v", zenturie.Name, semester, err)
-				errorCount++
-				continue
-			}
+	for attempt := 0; ; attempt++ {
+		result := fetchICSFile(ctx, client, job, state, known)
+		if result.Outcome != fetchFailed || !result.Retryable || attempt >= retries {
+			return result
+		}
 
-			// Validate that we got actual ICS content
-			if len(body) == 0 {
-				log.Printf("  WARNING: Empty ICS file for %s semester %d", zenturie.Name, semester)
-				errorCount++
-				continue
-			}
+		log.Printf("  Retrying %s in %s (attempt %d of %d)", job.URL, delay, attempt+2, retries+1)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return result
+		}
+		delay *= 2
+	}
+}
 
-			// Convert from Latin-1/ISO-8859-1 to UTF-8
-			utf8Body, err := convertToUTF8(body)
-			if err != nil {
-				log.Printf("  WARNING: Failed to convert encoding for %s semester %d: %v", zenturie.Name, semester, err)
-				// Try to use as-is
-				utf8Body = body
-			}
+// fetchICSFile downloads a single feed file, using a conditional request if the file was fetched before
+func fetchICSFile(ctx context.Context, client *http.Client, job fetchJob, state models.FeedFetchState, known bool) fetchResult {
+	log.Printf("  Attempting to fetch: %s", job.URL)
 
-			log.Printf("  Successfully fetched ICS for %s semester %d (%d bytes)", zenturie.Name, semester, len(body))
-			successCount++
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.URL, nil)
+	if err != nil {
+		log.Printf("  ERROR building request for %s semester %d: %v", job.Zenturie, job.Semester, err)
+		return fetchFailure(job, err)
+	}
 
-			icsData = append(icsData, ICSData{
-				Zenturie: zenturie.Name,
-				Content:  string(utf8Body),
-			})
+	// Conditional request: only download the file if it changed since the last import
+	if known {
+		if state.ETag != nil {
+			req.Header.Set("If-None-Match", *state.ETag)
+		}
+		if state.LastModified != nil {
+			req.Header.Set("If-Modified-Since", *state.LastModified)
 		}
 	}
 
-	log.Printf("Fetch summary: %d successful, %d errors", successCount, errorCount)
-	log.Printf("Total ICS files fetched: %d", len(icsData))
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("  ERROR fetching ICS for %s semester %d: %v", job.Zenturie, job.Semester, err)
+		if ctx.Err() != nil {
+			return fetchFailure(job, err)
+		}
+		return transientFailure(job, err)
+	}
+	defer resp.Body.Close()
+
+	// Check if file exists (404 means semester doesn't exist)
+	if resp.StatusCode == 404 {
+		log.Printf("  No ICS file found for %s semester %d (404)", job.Zenturie, job.Semester)
+		return fetchResult{Outcome: fetchNotFound}
+	}
+
+	// 304 Not Modified: skip parse and import for this file
+	if resp.StatusCode == http.StatusNotModified && known {
+		log.Printf("  ICS for %s semester %d not modified (304), skipping", job.Zenturie, job.Semester)
+		return fetchResult{
+			Outcome: fetchUnchanged,
+			Data: ICSData{
+				Zenturie:    job.Zenturie,
+				SourceFile:  job.SourceFile,
+				URL:         job.URL,
+				ContentHash: state.ContentHash,
+				Unchanged:   true,
+			},
+		}
+	}
 
-	// Return error only if we couldn't fetch ANY files
-	if len(icsData) == 0 && len(zenturien) > 0 {
-		return nil, fmt.Errorf("failed to fetch any ICS files (tried %d zenturien)", len(zenturien))
+	if resp.StatusCode != 200 {
+		log.Printf("  ERROR: Unexpected status code %d for %s semester %d", resp.StatusCode, job.Zenturie, job.Semester)
+		err := fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
+			return transientFailure(job, err)
+		}
+		return fetchFailure(job, err)
 	}
 
-	return icsData, nil
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("  ERROR reading ICS for %s semester %d: %v", job.Zenturie, job.Semester, err)
+		return transientFailure(job, err)
+	}
+
+	// Validate that we got actual ICS content
+	if len(body) == 0 {
+		log.Printf("  WARNING: Empty ICS file for %s semester %d", job.Zenturie, job.Semester)
+		return fetchFailure(job, fmt.Errorf("empty file"))
+	}
+
+	// Server ignored the conditional headers but content is identical: skip as well
+	contentHash := hashContent(body)
+	if known && state.ContentHash == contentHash {
+		log.Printf("  ICS for %s semester %d unchanged (same content hash), skipping", job.Zenturie, job.Semester)
+		return fetchResult{
+			Outcome: fetchUnchanged,
+			Data: ICSData{
+				Zenturie:     job.Zenturie,
+				SourceFile:   job.SourceFile,
+				URL:          job.URL,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				ContentHash:  contentHash,
+				Unchanged:    true,
+			},
+		}
+	}
+
+	// Convert to UTF-8 (NAK feeds are Latin-1/ISO-8859-1)
+	utf8Body, err := decodeFeedContent(body, job.Encoding)
+	if err != nil {
+		log.Printf("  WARNING: Failed to convert encoding for %s semester %d: %v", job.Zenturie, job.Semester, err)
+		// Try to use as-is
+		utf8Body = body
+	}
+
+	log.Printf("  Successfully fetched ICS for %s semester %d (%d bytes)", job.Zenturie, job.Semester, len(body))
+
+	return fetchResult{
+		Outcome: fetchOK,
+		Data: ICSData{
+			Zenturie:     job.Zenturie,
+			SourceFile:   job.SourceFile,
+			Content:      string(utf8Body),
+			URL:          job.URL,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			ContentHash:  contentHash,
+		},
+	}
+}
+
+// fetchFailure builds the result of a failed download
+func fetchFailure(job fetchJob, err error) fetchResult {
+	return fetchResult{
+		Outcome: fetchFailed,
+		Data: ICSData{
+			Zenturie:   job.Zenturie,
+			SourceFile: job.SourceFile,
+			URL:        job.URL,
+			Error:      err.Error(),
+		},
+	}
+}
+
+// transientFailure builds the result of a failed download that is worth retrying
+func transientFailure(job fetchJob, err error) fetchResult {
+	result := fetchFailure(job, err)
+	result.Retryable = true
+	return result
+}
+
+// feedHost returns the host part of a feed URL, used as key for the per-host cap
+func feedHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Host
+}
+
+// buildFeedURL expands the feed source URL pattern for a zenturie and semester
+// Example: "{base}/{zenturie}_{semester}.ics" -> "https://.../I24c_3.ics"
+func buildFeedURL(source *models.FeedSource, zenturieName string, semester int) string {
+	pattern := source.URLPattern
+	if pattern == "" {
+		pattern = DefaultFeedURLPattern
+	}
+
+	replacer := strings.NewReplacer(
+		"{base}", strings.TrimSuffix(source.BaseURL, "/"),
+		"{zenturie}", zenturieName,
+		"{semester}", fmt.Sprintf("%d", semester),
+	)
+	return replacer.Replace(pattern)
 }
 
 // ParseICSFiles parses ICS files and extracts events
-func ParseICSFiles(icsData []ICSData) (map[string][]TimetableEvent, error) {
+// Times without TZID ("floating") are interpreted in loc, the tenant time zone
+func ParseICSFiles(icsData []ICSData, loc *time.Location) (map[string][]TimetableEvent, error) {
 	events := make(map[string][]TimetableEvent)
+	window := currentRecurrenceWindow()
 
 	for _, data := range icsData {
+		// Unchanged files were already imported in a previous run, failed ones have no content
+		if data.Unchanged || data.Error != "" {
+			continue
+		}
+
+		// Pre-parsed events (non-ICS adapters)
+		if data.Events != nil {
+			for _, event := range data.Events {
+				event.SourceFile = data.SourceFile
+				events[data.Zenturie] = append(events[data.Zenturie], event)
+			}
+			log.Printf("Parsed %d events for %s", len(data.Events), data.Zenturie)
+			continue
+		}
+
 		reader := strings.NewReader(data.Content)
 		decoder := ical.NewDecoder(reader)
 
@@ -166,16 +534,11 @@ func ParseICSFiles(icsData []ICSData) (map[string][]TimetableEvent, error) {
 				continue
 			}
 
-			for _, component := range cal.Children {
-				if component.Name != "VEVENT" {
-					continue
-				}
-
-				event := parseEvent(component)
-				if event != nil {
-					events[data.Zenturie] = append(events[data.Zenturie], *event)
-					eventCount++
-				}
+			// Recurring events are expanded into one event per occurrence
+			for _, event := range parseCalendarEvents(cal, window, loc) {
+				event.SourceFile = data.SourceFile
+				events[data.Zenturie] = append(events[data.Zenturie], event)
+				eventCount++
 			}
 		}
 
@@ -190,7 +553,8 @@ func ParseICSFiles(icsData []ICSData) (map[string][]TimetableEvent, error) {
 }
 
 // parseEvent parses a single VEVENT component
-func parseEvent(component *ical.Component) *TimetableEvent {
+// Times are resolved with tz (TZID, embedded VTIMEZONE or the tenant time zone) and stored as UTC
+func parseEvent(component *ical.Component, tz *tzResolver) *TimetableEvent {
 	event := &TimetableEvent{}
 
 	// Get UID
@@ -214,280 +578,568 @@ func parseEvent(component *ical.Component) *TimetableEvent {
 	}
 
 	// Get Start Time
+	var start icsTime
 	if prop := component.Props.Get("DTSTART"); prop != nil {
-		if t, err := parseICSTime(prop.Value); err == nil {
-			event.StartTime = t
+		if t, err := tz.parseProp(prop); err == nil {
+			start = t
+			event.StartTime = t.UTC()
+			event.IsAllDay = t.AllDay
 		}
 	}
 
 	// Get End Time
 	if prop := component.Props.Get("DTEND"); prop != nil {
-		if t, err := parseICSTime(prop.Value); err == nil {
-			event.EndTime = t
+		if t, err := tz.parseProp(prop); err == nil {
+			event.EndTime = t.UTC()
 		}
 	}
 
-	// Extract professor and course code from summary
-	extractMetadata(event)
+	// All-day events without DTEND last one day (RFC 5545 3.6.1)
+	if event.IsAllDay && event.EndTime.IsZero() && !event.StartTime.IsZero() {
+		end := start
+		end.Wall = start.Wall.AddDate(0, 0, 1)
+		event.EndTime = end.UTC()
+	}
 
 	return event
 }
 
-// parseICSTime parses ICS time format and converts to UTC
-func parseICSTime(value string) (time.Time, error) {
-	// Format with Z suffix is already UTC
-	if strings.HasSuffix(value, "Z") {
-		t, err := time.Parse("20060102T150405Z", value)
-		if err == nil {
-			return t.UTC(), nil
+// ImportEventsToDatabase imports parsed events of one tenant to database
+// Every zenturie, course, room and timetable row is scoped to opts.TenantID.
+// Creates, updates and cancellations are written to the timetable change history.
+// With opts.DryRun the import runs in a transaction that is rolled back, and the
+// changes it would have made are returned in ImportStatistics.Preview.
+func ImportEventsToDatabase(opts ImportOptions, eventsMap map[string][]TimetableEvent) (*ImportStatistics, error) {
+	if !opts.DryRun {
+		return importEvents(config.DB, opts, eventsMap)
+	}
+
+	var stats *ImportStatistics
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if stats, err = importEvents(tx, opts, eventsMap); err != nil {
+			return err
 		}
+		return errDryRunRollback
+	})
+	if err != nil && !errors.Is(err, errDryRunRollback) {
+		return nil, err
 	}
 
-	// Load Europe/Berlin timezone for local times
-	berlinTZ, err := time.LoadLocation("Europe/Berlin")
+	return stats, nil
+}
+
+// errDryRunRollback rolls back the transaction of a dry-run import
+var errDryRunRollback = errors.New("dry run: rolling back")
+
+// importEvents imports parsed events using db (config.DB or a dry-run transaction)
+// Existing rows are loaded once per zenturie, each source file is then applied in its
+// own transaction with batched upserts, so a file is either imported completely or not at all.
+func importEvents(db *gorm.DB, opts ImportOptions, eventsMap map[string][]TimetableEvent) (*ImportStatistics, error) {
+	// Zenturien whose files contain no events at all are imported as well
+	zenturien := make(map[string][]TimetableEvent, len(eventsMap))
+	for zenturieName, events := range eventsMap {
+		zenturien[zenturieName] = events
+	}
+	for zenturieName := range opts.SourceFiles {
+		if _, ok := zenturien[zenturieName]; !ok {
+			zenturien[zenturieName] = nil
+		}
+	}
+
+	if len(zenturien) == 0 {
+		log.Println("WARNING: No events to import")
+		return &ImportStatistics{}, nil
+	}
+
+	rules, err := LoadExtractionRules(db, opts.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	grammar, err := LoadRoomGrammar(db, opts.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := GetRetentionPolicy(opts.TenantID)
 	if err != nil {
-		// Fallback to UTC if timezone loading fails
-		berlinTZ = time.UTC
-		log.Printf("WARNING: Failed to load Europe/Berlin timezone, using UTC: %v", err)
+		return nil, err
 	}
 
-	// Format without Z is local time (Europe/Berlin for NAK)
-	if len(value) == 15 && strings.Contains(value, "T") {
-		// Parse as local time in Europe/Berlin timezone
-		t, err := time.ParseInLocation("20060102T150405", value, berlinTZ)
-		if err == nil {
-			return t.UTC(), nil // Convert to UTC
-		}
+	imp := &eventImporter{
+		db:        db,
+		opts:      opts,
+		rules:     rules,
+		grammar:   grammar,
+		courses:   make(map[string]*uint),
+		rooms:     make(map[string]roomRef),
+		lecturers: make(map[string]*uint),
+	}
+	if policy.TimetableDays > 0 {
+		imp.retentionCutoff = time.Now().UTC().AddDate(0, 0, -policy.TimetableDays)
+	}
+	if opts.DryRun {
+		imp.preview = newImportPreview()
 	}
 
-	// Date only format
-	if len(value) == 8 {
-		t, err := time.ParseInLocation("20060102", value, berlinTZ)
-		if err == nil {
-			return t.UTC(), nil // Convert to UTC
-		}
+	stats := &ImportStatistics{Preview: imp.preview}
+
+	for zenturieName, events := range zenturien {
+		log.Printf("Importing events for zenturie: %s (%d events)", zenturieName, len(events))
+
+		zs := imp.importZenturie(zenturieName, events)
+
+		log.Printf("Zenturie %s: %d created, %d updated, %d unchanged, %d cancelled, %d errors",
+			zenturieName, zs.Created, zs.Updated, zs.Unchanged, zs.Cancelled, zs.Errors)
+
+		stats.EventsCreated += zs.Created
+		stats.EventsUpdated += zs.Updated
+		stats.EventsUnchanged += zs.Unchanged
+		stats.EventsCancelled += zs.Cancelled
+		stats.Errors += zs.Errors
+		stats.Zenturien = append(stats.Zenturien, zs)
+		stats.Quarantined = append(stats.Quarantined, zs.Quarantined...)
 	}
 
-	return time.Time{}, fmt.Errorf("unable to parse time: %s", value)
+	log.Printf("Import summary: %d created, %d updated, %d unchanged, %d cancelled, %d errors",
+		stats.EventsCreated, stats.EventsUpdated, stats.EventsUnchanged, stats.EventsCancelled, stats.Errors)
+
+	return stats, nil
+}
+
+// timetableBatchSize is the number of rows per INSERT ... ON CONFLICT statement
+const timetableBatchSize = 500
+
+// timetableUpsertColumns are overwritten when an imported event already exists
+var timetableUpsertColumns = []string{
+	"course_id", "room_id", "lecturer_id", "summary", "description", "location", "start_time", "end_time", "is_all_day",
+	"professor", "course_type", "course_code", "online_link", "notes", "source_file", "status", "cancelled_at",
 }
 
-// extractMetadata extracts professor, course code, etc. from summary
-func extractMetadata(event *TimetableEvent) {
-	// Example: "V I231 Algorithmen (Prof. Dr. Müller)"
-	summary := event.Summary
+// eventImporter holds the state of one importEvents call
+type eventImporter struct {
+	db            *gorm.DB
+	opts          ImportOptions
+	rules         *ExtractionRuleSet
+	grammar       *RoomGrammar
+	preview       *ImportPreview // Dry runs only
+	debugLogCount int            // Only log first 5 comparisons for debugging
+
+	// New events that ended before this were deleted by the retention job and are not imported again
+	retentionCutoff time.Time
+
+	// Lookup caches, reset when a file transaction is rolled back
+	courses   map[string]*uint   // Course code -> course ID
+	rooms     map[string]roomRef // Room location -> room reference
+	lecturers map[string]*uint   // Normalized lecturer name -> lecturer ID
+}
+
+// roomRef is the cached result of findOrCreateRoom
+type roomRef struct {
+	RoomIDs       []uint
+	ExtraLocation string
+}
 
-	// Extract course type (V, Z, etc.)
-	if len(summary) > 0 {
-		parts := strings.Fields(summary)
-		if len(parts) > 0 {
-			event.CourseType = parts[0]
+// importZenturie imports the events of one zenturie, one transaction per source file
+func (imp *eventImporter) importZenturie(zenturieName string, events []TimetableEvent) ZenturieStatistics {
+	tenantID := imp.opts.TenantID
+	stats := ZenturieStatistics{Zenturie: zenturieName}
+
+	// Group events by source file, keeping feed order
+	var sourceFiles []string
+	byFile := make(map[string][]TimetableEvent)
+	for _, event := range events {
+		if _, ok := byFile[event.SourceFile]; !ok {
+			sourceFiles = append(sourceFiles, event.SourceFile)
 		}
-		if len(parts) > 1 {
-			event.CourseCode = parts[1]
+		byFile[event.SourceFile] = append(byFile[event.SourceFile], event)
+	}
+	for _, sourceFile := range imp.opts.SourceFiles[zenturieName] {
+		if _, ok := byFile[sourceFile]; !ok {
+			sourceFiles = append(sourceFiles, sourceFile)
+			byFile[sourceFile] = nil
 		}
 	}
 
-	// Extract professor from parentheses
-	if start := strings.Index(summary, "("); start != -1 {
-		if end := strings.Index(summary[start:], ")"); end != -1 {
-			event.Professor = strings.TrimSpace(summary[start+1 : start+end])
+	// Find or create zenturie (within tenant)
+	var zenturie models.Zenturie
+	result := imp.db.Where("tenant_id = ? AND name = ?", tenantID, zenturieName).First(&zenturie)
+
+	if result.Error != nil {
+		// Create zenturie if not exists
+		year := extractYear(zenturieName)
+		zenturie = models.Zenturie{
+			TenantID: tenantID,
+			Name:     zenturieName,
+			Year:     year,
+		}
+		if err := imp.db.Create(&zenturie).Error; err != nil {
+			log.Printf("ERROR creating zenturie %s: %v", zenturieName, err)
+			stats.Errors = len(events)
+			stats.FailedFiles = sourceFiles
+			return stats
 		}
+		log.Printf("Created new zenturie: %s", zenturieName)
 	}
-}
 
-// ImportEventsToDatabase imports parsed events to database
-func ImportEventsToDatabase(eventsMap map[string][]TimetableEvent) (*ImportStatistics, error) {
-	if len(eventsMap) == 0 {
-		log.Println("WARNING: No events to import")
-		return &ImportStatistics{}, nil
+	// Load all existing events of the zenturie at once, keyed by UID
+	var rows []models.Timetable
+	err := imp.db.Preload("Rooms", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("tenant_id = ? AND zenturien_id = ?", tenantID, zenturie.ID).
+		Find(&rows).Error
+	if err != nil {
+		log.Printf("ERROR loading timetable of zenturie %s: %v", zenturieName, err)
+		stats.Errors = len(events)
+		stats.FailedFiles = sourceFiles
+		return stats
+	}
+	existing := make(map[string]models.Timetable, len(rows))
+	for _, row := range rows {
+		existing[row.UID] = row
+	}
+
+	for _, sourceFile := range sourceFiles {
+		// Files without a name are never reconciled, so they cannot drop events either
+		if imp.opts.Guards != nil && sourceFile != "" {
+			guard := imp.opts.Guards.check(sourceFile, byFile[sourceFile], existing)
+			if len(guard.Reasons) > 0 {
+				log.Printf("WARNING: Quarantined %s for zenturie %s: %s", sourceFile, zenturieName, strings.Join(guard.Reasons, "; "))
+				file, err := imp.quarantine(&zenturie, sourceFile, byFile[sourceFile], guard)
+				if err != nil {
+					log.Printf("ERROR quarantining %s for zenturie %s: %v", sourceFile, zenturieName, err)
+					stats.Errors += len(byFile[sourceFile])
+					stats.FailedFiles = append(stats.FailedFiles, sourceFile)
+					continue
+				}
+				stats.Quarantined = append(stats.Quarantined, *file)
+				continue
+			}
+		}
+
+		fileStats, err := imp.importSourceFile(&zenturie, sourceFile, byFile[sourceFile], existing)
+		if err != nil {
+			log.Printf("ERROR importing %s for zenturie %s, rolled back: %v", sourceFile, zenturieName, err)
+			stats.Errors += len(byFile[sourceFile])
+			stats.FailedFiles = append(stats.FailedFiles, sourceFile)
+
+			// Courses and rooms created in the rolled back transaction are gone
+			imp.courses = make(map[string]*uint)
+			imp.rooms = make(map[string]roomRef)
+			imp.lecturers = make(map[string]*uint)
+			continue
+		}
+
+		stats.Created += fileStats.Created
+		stats.Updated += fileStats.Updated
+		stats.Unchanged += fileStats.Unchanged
+		stats.Cancelled += fileStats.Cancelled
+		stats.Errors += fileStats.Errors
 	}
 
-	totalCreated := 0
-	totalUpdated := 0
-	totalUnchanged := 0
-	totalErrors := 0
-	debugLogCount := 0 // Only log first 5 changes for debugging
+	return stats
+}
 
-	for zenturieName, events := range eventsMap {
-		log.Printf("Importing events for zenturie: %s (%d events)", zenturieName, len(events))
+// importSourceFile applies the events of one source file in a single transaction:
+// new and changed events are written with batched upserts, vanished events are cancelled
+// and the change history is recorded. existing is updated after the commit.
+func (imp *eventImporter) importSourceFile(zenturie *models.Zenturie, sourceFile string, events []TimetableEvent, existing map[string]models.Timetable) (ZenturieStatistics, error) {
+	tenantID := imp.opts.TenantID
 
-		// Find or create zenturie
-		var zenturie models.Zenturie
-		result := config.DB.Where("name = ?", zenturieName).First(&zenturie)
+	var (
+		stats     ZenturieStatistics
+		upserts   []models.Timetable
+		cancelled []models.Timetable
+		preview   ImportPreview
+	)
 
-		if result.Error != nil {
-			// Create zenturie if not exists
-			year := extractYear(zenturieName)
-			zenturie = models.Zenturie{
-				Name: zenturieName,
-				Year: year,
-			}
-			if err := config.DB.Create(&zenturie).Error; err != nil {
-				log.Printf("ERROR creating zenturie %s: %v", zenturieName, err)
-				totalErrors++
-				continue
-			}
-			log.Printf("Created new zenturie: %s", zenturieName)
-		}
+	err := imp.db.Transaction(func(tx *gorm.DB) error {
+		changes := newChangeRecorder(tx, tenantID, imp.opts.RunID)
 
-		createdCount := 0
-		updatedCount := 0
-		unchangedCount := 0
-		errorCount := 0
+		// Events to create or update, with the diff against the stored row
+		// (nil for new events, empty if only the lecturer assignment changed)
+		var fieldChanges [][]fieldChange
+		var seenUIDs []string
 
-		// Import events
-		for _, event := range events {
+		for _, event := range dedupeEvents(events) {
 			// Validate event has required fields
 			if event.UID == "" || event.Summary == "" {
 				log.Printf("WARNING: Skipping invalid event (missing UID or Summary)")
-				errorCount++
+				stats.Errors++
 				continue
 			}
+			seenUIDs = append(seenUIDs, event.UID)
 
-			// Clean up summary: Replace -\ with -/ first, then take only part before first \,
-			cleanSummary := cleanupSummary(event.Summary)
-
-			// Find or create course
-			var courseID *uint
-			if event.CourseCode != "" && event.CourseCode != "WP" {
-				course := findOrCreateCourse(event.CourseCode, cleanSummary, zenturie.Year)
-				if course != nil {
-					courseID = &course.ID
-				}
+			old, ok := existing[event.UID]
+			if !ok && !imp.retentionCutoff.IsZero() && event.EndTime.Before(imp.retentionCutoff) {
+				continue
 			}
 
-			// Find or create room(s)
-			// PRIORITY: Extract room from Description field (format: "Raum: A105")
-			// FALLBACK: Use Location field if no room found in Description
-			var roomID *uint
-			extraLocation := ""
-			roomLocation := ""
-
-			// Try to extract room from description first
-			if event.Description != "" {
-				roomLocation = extractRoomFromDescription(event.Description)
-			}
+			timetable := imp.buildTimetable(tx, zenturie, event)
 
-			// Fallback to Location field if no room in description
-			if roomLocation == "" && event.Location != "" {
-				roomLocation = event.Location
+			if !ok {
+				upserts = append(upserts, timetable)
+				fieldChanges = append(fieldChanges, nil)
+				continue
 			}
 
-			// Parse and create room if we found a location
-			if roomLocation != "" {
-				roomID, extraLocation = findOrCreateRoom(roomLocation)
+			// Check if anything actually changed
+			diff := diffTimetable(&old, &timetable)
+			if imp.debugLogCount < 5 {
+				// Enable detailed logging for first 5 comparisons
+				logTimetableChanges(old.UID, diff)
+				imp.debugLogCount++
 			}
 
-			// Extract professor from description if not already set
-			// PRIORITY: Extract from Description field (format: "Dozent: Prof. Dr. Müller")
-			// FALLBACK: Use extractMetadata result (from Summary field)
-			if event.Professor == "" && event.Description != "" {
-				professorFromDesc := extractProfessorFromDescription(event.Description)
-				if professorFromDesc != "" {
-					event.Professor = professorFromDesc
+			if len(diff) == 0 {
+				if !compareNullableUint(old.LecturerID, timetable.LecturerID) {
+					// Lecturer assigned to an existing event, not a change of the event itself
+					upserts = append(upserts, timetable)
+					fieldChanges = append(fieldChanges, []fieldChange{})
 				}
+				stats.Unchanged++
+				continue
 			}
-
-			// Check if event already exists (by UID AND ZenturienID)
-			// This allows the same UID to exist for different zenturien (Wahlpflichtmodule)
-			var existing models.Timetable
-			result := config.DB.Where("uid = ? AND zenturien_id = ?", event.UID, zenturie.ID).First(&existing)
-
-			locationPtr := &extraLocation
-			if extraLocation == "" {
-				locationPtr = nil
+			if old.CancelledAt != nil {
+				// Event is back in the feed: the upsert clears the cancellation timestamp
+				log.Printf("Event %s reappeared in feed, marked as active again", event.UID)
 			}
+			upserts = append(upserts, timetable)
+			fieldChanges = append(fieldChanges, diff)
+		}
 
-			// Create pointers only if strings are not empty
-			var descriptionPtr *string
-			if event.Description != "" {
-				descriptionPtr = &event.Description
+		if len(upserts) > 0 {
+			// Room assignments are written by syncTimetableRooms, the IDs are only known after the upsert
+			err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "uid"}, {Name: "zenturien_id"}},
+				DoUpdates: clause.AssignmentColumns(timetableUpsertColumns),
+			}).CreateInBatches(&upserts, timetableBatchSize).Error
+			if err != nil {
+				return fmt.Errorf("failed to upsert %d events: %w", len(upserts), err)
+			}
+			if err := syncTimetableRooms(tx, upserts); err != nil {
+				return err
 			}
+		}
 
-			var professorPtr *string
-			if event.Professor != "" {
-				professorPtr = &event.Professor
+		for i := range upserts {
+			tt := &upserts[i]
+			if fieldChanges[i] != nil && len(fieldChanges[i]) == 0 {
+				continue
+			}
+			if fieldChanges[i] == nil {
+				changes.created(tt)
+				stats.Created++
+				if imp.preview != nil {
+					preview.Created = append(preview.Created, previewEvent(zenturie.Name, tt))
+				}
+				continue
 			}
 
-			var courseTypePtr *string
-			if event.CourseType != "" {
-				courseTypePtr = &event.CourseType
+			changes.updated(tt, fieldChanges[i])
+			stats.Updated++
+			if imp.preview != nil {
+				updated := previewEvent(zenturie.Name, tt)
+				for _, change := range fieldChanges[i] {
+					updated.Changes = append(updated.Changes, PreviewFieldChange{
+						Field:    change.Field,
+						OldValue: changes.resolve(change.Field, change.OldValue),
+						NewValue: changes.resolve(change.Field, change.NewValue),
+					})
+				}
+				preview.Updated = append(preview.Updated, updated)
 			}
+		}
 
-			var courseCodePtr *string
-			if event.CourseCode != "" {
-				courseCodePtr = &event.CourseCode
+		// Reconcile: mark events that vanished from their source file as cancelled
+		if sourceFile != "" {
+			var err error
+			if cancelled, err = cancelVanishedEvents(tx, tenantID, zenturie.ID, sourceFile, seenUIDs); err != nil {
+				return fmt.Errorf("failed to reconcile: %w", err)
+			}
+			changes.cancelled(cancelled)
+			stats.Cancelled = len(cancelled)
+			if imp.preview != nil {
+				for i := range cancelled {
+					preview.Cancelled = append(preview.Cancelled, previewEvent(zenturie.Name, &cancelled[i]))
+				}
 			}
 
-			timetable := models.Timetable{
-				ZenturienID: zenturie.ID,
-				CourseID:    courseID,
-				RoomID:      roomID,
-				UID:         event.UID,
-				Summary:     cleanSummary,
-				Description: descriptionPtr,
-				Location:    locationPtr,
-				StartTime:   event.StartTime,
-				EndTime:     event.EndTime,
-				Professor:   professorPtr,
-				CourseType:  courseTypePtr,
-				CourseCode:  courseCodePtr,
+			// A quarantined older version of the file is outdated now
+			if err := supersedeQuarantines(tx, tenantID, zenturie.Name, sourceFile); err != nil {
+				return fmt.Errorf("failed to supersede quarantined files: %w", err)
 			}
+		}
 
-			if result.Error != nil {
-				// Create new event
-				if err := config.DB.Create(&timetable).Error; err != nil {
-					log.Printf("ERROR creating timetable event %s: %v", event.UID, err)
-					errorCount++
-				} else {
-					createdCount++
-				}
-			} else {
-				// Check if anything actually changed
-				hasChanged := false
-				if debugLogCount < 5 {
-					// Enable detailed logging for first 5 comparisons
-					hasChanged = hasChangesDetailed(&existing, &timetable, true)
-					debugLogCount++
-				} else {
-					hasChanged = hasChangesDetailed(&existing, &timetable, false)
-				}
+		return changes.flush()
+	})
+	if err != nil {
+		return ZenturieStatistics{}, err
+	}
 
-				if hasChanged {
-					// Update existing event only if there are changes
-					if err := config.DB.Model(&existing).Updates(timetable).Error; err != nil {
-						log.Printf("ERROR updating timetable event %s: %v", event.UID, err)
-						errorCount++
-					} else {
-						updatedCount++
-					}
-				} else {
-					// No changes, count as unchanged
-					unchangedCount++
-				}
+	// Keep the in-memory state in sync for further files of the zenturie
+	for _, tt := range upserts {
+		existing[tt.UID] = tt
+	}
+	for _, tt := range cancelled {
+		if row, ok := existing[tt.UID]; ok {
+			row.Status = models.TimetableStatusCancelled
+			existing[tt.UID] = row
+		}
+	}
+
+	if imp.preview != nil {
+		imp.preview.Created = append(imp.preview.Created, preview.Created...)
+		imp.preview.Updated = append(imp.preview.Updated, preview.Updated...)
+		imp.preview.Cancelled = append(imp.preview.Cancelled, preview.Cancelled...)
+	}
+
+	return stats, nil
+}
+
+// buildTimetable converts a parsed event into a timetable row, resolving its course and room
+func (imp *eventImporter) buildTimetable(tx *gorm.DB, zenturie *models.Zenturie, event TimetableEvent) models.Timetable {
+	tenantID := imp.opts.TenantID
+
+	// Professor, room, course type/code, online link and notes from the tenant's extraction rules
+	imp.rules.Apply(&event)
+
+	// Clean up summary: Replace -\ with -/ first, then take only part before first \,
+	cleanSummary := cleanupSummary(event.Summary)
+
+	// Find or create course
+	var courseID *uint
+	if event.CourseCode != "" && event.CourseCode != "WP" {
+		id, ok := imp.courses[event.CourseCode]
+		if !ok {
+			if course := findOrCreateCourse(tx, tenantID, event.CourseCode, cleanSummary, zenturie.Year); course != nil {
+				id = &course.ID
 			}
+			imp.courses[event.CourseCode] = id
+		}
+		courseID = id
+	}
+
+	// Find or create lecturer, name variants share one lecturer
+	var lecturerID *uint
+	if normalized := NormalizeLecturerName(event.Professor); normalized != "" {
+		id, ok := imp.lecturers[normalized]
+		if !ok {
+			id = findOrCreateLecturer(tx, tenantID, event.Professor)
+			imp.lecturers[normalized] = id
+		}
+		lecturerID = id
+	}
+
+	// Find or create room(s) of the location extracted by the rules (e.g. "Raum: A105" or LOCATION)
+	var roomID *uint
+	var rooms []models.TimetableRoom
+	extraLocation := ""
+	if event.Room != "" {
+		ref, ok := imp.rooms[event.Room]
+		if !ok {
+			ref.RoomIDs, ref.ExtraLocation = findOrCreateRoom(tx, imp.grammar, tenantID, event.Room)
+			imp.rooms[event.Room] = ref
 		}
+		for i, id := range ref.RoomIDs {
+			rooms = append(rooms, models.TimetableRoom{RoomID: id, Position: i})
+		}
+		if len(ref.RoomIDs) > 0 {
+			roomID = &ref.RoomIDs[0]
+		}
+		extraLocation = ref.ExtraLocation
+	}
+
+	return models.Timetable{
+		TenantID:    tenantID,
+		ZenturienID: zenturie.ID,
+		CourseID:    courseID,
+		RoomID:      roomID,
+		LecturerID:  lecturerID,
+		Rooms:       rooms,
+		UID:         event.UID,
+		Summary:     cleanSummary,
+		Description: optionalString(event.Description),
+		Location:    optionalString(extraLocation),
+		StartTime:   event.StartTime,
+		EndTime:     event.EndTime,
+		IsAllDay:    event.IsAllDay,
+		Professor:   optionalString(event.Professor),
+		CourseType:  optionalString(event.CourseType),
+		OnlineLink:  optionalString(event.OnlineLink),
+		Notes:       optionalString(event.Notes),
+		CourseCode:  optionalString(event.CourseCode),
+		SourceFile:  optionalString(event.SourceFile),
+		Status:      models.TimetableStatusActive,
+	}
+}
 
-		log.Printf("Zenturie %s: %d created, %d updated, %d unchanged, %d errors",
-			zenturieName, createdCount, updatedCount, unchangedCount, errorCount)
+// dedupeEvents removes repeated UIDs of a file (one upsert statement can't touch a row twice)
+// The last occurrence wins, at the position of the first one
+func dedupeEvents(events []TimetableEvent) []TimetableEvent {
+	index := make(map[string]int, len(events))
+	result := make([]TimetableEvent, 0, len(events))
+	for _, event := range events {
+		if i, ok := index[event.UID]; ok && event.UID != "" {
+			result[i] = event
+			continue
+		}
+		index[event.UID] = len(result)
+		result = append(result, event)
+	}
+	return result
+}
 
-		totalCreated += createdCount
-		totalUpdated += updatedCount
-		totalUnchanged += unchangedCount
-		totalErrors += errorCount
+// optionalString returns nil for empty strings
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
+}
 
-	log.Printf("Import summary: %d created, %d updated, %d unchanged, %d errors",
-		totalCreated, totalUpdated, totalUnchanged, totalErrors)
+// cancelVanishedEvents marks active events of a zenturie's source file as cancelled
+// if their UID is no longer present in the file
+// Only called for files that were fetched and parsed in this run, so missing (404) files
+// never cancel their events. An empty file cancels all of them; guarded imports quarantine
+// such files instead (see ImportGuards.check), as they are more likely a broken download.
+func cancelVanishedEvents(db *gorm.DB, tenantID, zenturieID uint, sourceFile string, presentUIDs []string) ([]models.Timetable, error) {
+	// Events that already ended are left alone: feeds may drop past events, and
+	// recurring events are only expanded within a window around now
+	query := db.Select("id", "uid", "zenturien_id", "summary", "start_time", "end_time").
+		Where("tenant_id = ? AND zenturien_id = ? AND source_file = ? AND status = ? AND end_time >= ?",
+			tenantID, zenturieID, sourceFile, models.TimetableStatusActive, time.Now().UTC())
+	if len(presentUIDs) > 0 {
+		query = query.Where("uid NOT IN ?", presentUIDs)
+	}
+
+	var vanished []models.Timetable
+	if err := query.Find(&vanished).Error; err != nil {
+		return nil, err
+	}
 
-	stats := &ImportStatistics{
-		EventsCreated:   totalCreated,
-		EventsUpdated:   totalUpdated,
-		EventsUnchanged: totalUnchanged,
-		Errors:          totalErrors,
+	if len(vanished) == 0 {
+		return nil, nil
 	}
 
-	return stats, nil
+	ids := make([]uint, len(vanished))
+	for i, tt := range vanished {
+		ids[i] = tt.ID
+	}
+
+	now := time.Now().UTC()
+	result := db.Model(&models.Timetable{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":       models.TimetableStatusCancelled,
+			"cancelled_at": now,
+		})
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	log.Printf("  Marked %d event(s) from %s as cancelled (removed from feed)", len(vanished), sourceFile)
+
+	return vanished, nil
 }
 
 // extractYear extracts year from zenturie name (e.g., "I24c" -> "24")
@@ -513,15 +1165,15 @@ func convertToUTF8(input []byte) ([]byte, error) {
 	return output, nil
 }
 
-// findOrCreateCourse finds or creates a course by module number
-func findOrCreateCourse(courseCode, summary, year string) *models.Course {
+// findOrCreateCourse finds or creates a course by module number within a tenant
+func findOrCreateCourse(db *gorm.DB, tenantID uint, courseCode, summary, year string) *models.Course {
 	if courseCode == "" {
 		return nil
 	}
 
 	// Try to find existing course
 	var course models.Course
-	result := config.DB.Where("module_number = ?", courseCode).First(&course)
+	result := db.Where("tenant_id = ? AND module_number = ?", tenantID, courseCode).First(&course)
 
 	if result.Error == nil {
 		return &course
@@ -535,12 +1187,13 @@ func findOrCreateCourse(courseCode, summary, year string) *models.Course {
 
 	// Create new course
 	course = models.Course{
+		TenantID:     tenantID,
 		ModuleNumber: courseCode,
 		Name:         courseName,
 		Year:         year,
 	}
 
-	if err := config.DB.Create(&course).Error; err != nil {
+	if err := db.Create(&course).Error; err != nil {
 		log.Printf("ERROR creating course %s: %v", courseCode, err)
 		return nil
 	}
@@ -566,173 +1219,53 @@ func extractCourseName(summary string) string {
 	return strings.TrimSpace(summary)
 }
 
-// extractProfessorFromDescription extracts the professor from the description field
-// Format: "Veranstaltung: ...\nDozent: Prof. Dr. Müller\nPause: ...\n..."
-// Returns the professor string after "Dozent: " up to the next newline
-func extractProfessorFromDescription(description string) string {
-	if description == "" {
-		return ""
-	}
-
-	// Search for "Dozent: " (case-sensitive as ICS files are consistent)
-	professorPrefix := "Dozent: "
-	idx := strings.Index(description, professorPrefix)
-	if idx == -1 {
-		// Try alternative: "Dozent:" without space
-		professorPrefix = "Dozent:"
-		idx = strings.Index(description, professorPrefix)
-		if idx == -1 {
-			return ""
-		}
-	}
-
-	// Extract everything after "Dozent: "
-	afterProfessor := description[idx+len(professorPrefix):]
-
-	// Find the next newline (handle both literal \n and actual newline)
-	var professor string
-
-	// Check for literal backslash-n first (common in ICS)
-	literalNewlineIdx := strings.Index(afterProfessor, "\\n")
-	actualNewlineIdx := strings.Index(afterProfessor, "\n")
-
-	// Use whichever comes first
-	newlineIdx := -1
-	if literalNewlineIdx != -1 && actualNewlineIdx != -1 {
-		if literalNewlineIdx < actualNewlineIdx {
-			newlineIdx = literalNewlineIdx
-		} else {
-			newlineIdx = actualNewlineIdx
-		}
-	} else if literalNewlineIdx != -1 {
-		newlineIdx = literalNewlineIdx
-	} else if actualNewlineIdx != -1 {
-		newlineIdx = actualNewlineIdx
-	}
-
-	if newlineIdx != -1 {
-		professor = afterProfessor[:newlineIdx]
-	} else {
-		// No newline found, take everything until the end
-		professor = afterProfessor
-	}
-
-	// Trim whitespace and handle "-" which means "no professor"
-	professor = strings.TrimSpace(professor)
-	if professor == "-" || professor == "" {
-		return ""
-	}
-
-	return professor
-}
-
-// extractRoomFromDescription extracts the room from the description field
-// Format: "Veranstaltung: ...\nDozent: ...\nRaum: A105\n..."
-// Returns the room string after "Raum: " up to the next newline
-func extractRoomFromDescription(description string) string {
-	if description == "" {
-		return ""
-	}
-
-	// Search for "Raum: " (case-sensitive as ICS files are consistent)
-	roomPrefix := "Raum: "
-	idx := strings.Index(description, roomPrefix)
-	if idx == -1 {
-		// Try alternative: "Raum:" without space
-		roomPrefix = "Raum:"
-		idx = strings.Index(description, roomPrefix)
-		if idx == -1 {
-			return ""
-		}
-	}
-
-	// Extract everything after "Raum: "
-	afterRoom := description[idx+len(roomPrefix):]
-
-	// Find the next newline (handle both literal \n and actual newline)
-	// ICS files can have literal "\n" (backslash + n) or actual newline character
-	var room string
-
-	// Check for literal backslash-n first (common in ICS)
-	literalNewlineIdx := strings.Index(afterRoom, "\\n")
-	actualNewlineIdx := strings.Index(afterRoom, "\n")
-
-	// Use whichever comes first
-	newlineIdx := -1
-	if literalNewlineIdx != -1 && actualNewlineIdx != -1 {
-		if literalNewlineIdx < actualNewlineIdx {
-			newlineIdx = literalNewlineIdx
-		} else {
-			newlineIdx = actualNewlineIdx
-		}
-	} else if literalNewlineIdx != -1 {
-		newlineIdx = literalNewlineIdx
-	} else if actualNewlineIdx != -1 {
-		newlineIdx = actualNewlineIdx
-	}
-
-	if newlineIdx != -1 {
-		room = afterRoom[:newlineIdx]
-	} else {
-		// No newline found, take everything until the end
-		room = afterRoom
-	}
-
-	// Trim whitespace and handle "-" which means "no room"
-	room = strings.TrimSpace(room)
-	if room == "-" || room == "" {
-		return ""
-	}
-
-	return room
-}
-
-// findOrCreateRoom finds or creates room(s) from location string
-// Returns the primary room ID and extra location info
-func findOrCreateRoom(location string) (*uint, string) {
+// findOrCreateRoom finds or creates room(s) of a tenant from location string
+// Rooms are recognized by the tenant's room grammar, other parts are kept as extra location info
+// Returns the IDs of all rooms in location order (the first one is the primary room) and extra location info
+func findOrCreateRoom(db *gorm.DB, grammar *RoomGrammar, tenantID uint, location string) ([]uint, string) {
 	if location == "" {
 		return nil, ""
 	}
 
 	// Split multiple rooms (e.g., "A103, EDV-A102")
 	rooms := strings.Split(location, ",")
-	var primaryRoomID *uint
+	var roomIDs []uint
 	extraRooms := []string{}
 
-	for i, roomStr := range rooms {
+	for _, roomStr := range rooms {
 		roomStr = strings.TrimSpace(roomStr)
 		if roomStr == "" {
 			continue
 		}
 
 		// Parse room number
-		roomNumber := parseRoomNumber(roomStr)
-		if roomNumber == "" {
+		match, ok := grammar.Parse(roomStr)
+		if !ok {
 			// Not a valid room, treat as extra location info
 			extraRooms = append(extraRooms, roomStr)
 			continue
 		}
+		roomNumber := match.RoomNumber
 
 		// Find or create room
 		var room models.Room
-		result := config.DB.Where("room_number = ?", roomNumber).First(&room)
+		result := db.Where("tenant_id = ? AND room_number = ?", tenantID, roomNumber).First(&room)
 
 		if result.Error != nil {
-			// Create new room
-			building, floor := extractBuildingAndFloor(roomNumber)
-
+			// Create new room with building and floor from the grammar
 			// Final cleanup - ensure all values are trimmed and have no backslashes
 			cleanRoomNumber := strings.TrimSpace(strings.ReplaceAll(roomNumber, "\\", ""))
-			cleanBuilding := strings.TrimSpace(strings.ReplaceAll(building, "\\", ""))
-			cleanFloor := strings.TrimSpace(strings.ReplaceAll(floor, "\\", ""))
+			cleanBuilding := strings.TrimSpace(strings.ReplaceAll(match.Building, "\\", ""))
+			cleanFloor := strings.TrimSpace(strings.ReplaceAll(match.Floor, "\\", ""))
 
 			room = models.Room{
+				TenantID:   tenantID,
 				RoomNumber: cleanRoomNumber,
 				Building:   cleanBuilding,
 				Floor:      cleanFloor,
 			}
 
-			if err := config.DB.Create(&room).Error; err != nil {
+			if err := db.Create(&room).Error; err != nil {
 				log.Printf("ERROR creating room %s: %v", cleanRoomNumber, err)
 				extraRooms = append(extraRooms, roomStr)
 				continue
@@ -740,104 +1273,37 @@ func findOrCreateRoom(location string) (*uint, string) {
 			log.Printf("Created new room: %s (Building: %s, Floor: %s)", cleanRoomNumber, cleanBuilding, cleanFloor)
 		}
 
-		// First room becomes the primary room
-		if i == 0 {
-			primaryRoomID = &room.ID
-		} else {
-			extraRooms = append(extraRooms, roomNumber)
+		// A room listed twice is assigned once
+		if !slices.Contains(roomIDs, room.ID) {
+			roomIDs = append(roomIDs, room.ID)
 		}
 	}
 
 	extraLocation := strings.Join(extraRooms, ", ")
-	return primaryRoomID, extraLocation
+	return roomIDs, extraLocation
 }
 
-// parseRoomNumber extracts room number from location string
-// Examples: "A104" -> "A104", "EDV-A102" -> "A102", "A 001" -> "A001"
-// Format: Letter + exactly 3 digits (4 characters total)
-// Stops at: backslash, newline, or other invalid characters
-func parseRoomNumber(location string) string {
-	// Trim initial whitespace
-	location = strings.TrimSpace(location)
-
-	// CRITICAL: Stop at backslash (prevents "C007\nAnmerkung" -> "C007NANMERKUNG")
-	if idx := strings.Index(location, "\\"); idx != -1 {
-		location = location[:idx]
-		location = strings.TrimSpace(location)
-	}
-
-	// Handle special prefixes like "EDV-"
-	if strings.Contains(location, "-") {
-		parts := strings.Split(location, "-")
-		if len(parts) == 2 {
-			location = strings.TrimSpace(parts[1])
+// syncTimetableRooms replaces the room assignments of upserted events with their Rooms
+func syncTimetableRooms(tx *gorm.DB, timetables []models.Timetable) error {
+	ids := make([]uint, 0, len(timetables))
+	var links []models.TimetableRoom
+	for _, tt := range timetables {
+		ids = append(ids, tt.ID)
+		for _, link := range tt.Rooms {
+			links = append(links, models.TimetableRoom{TimetableID: tt.ID, RoomID: link.RoomID, Position: link.Position})
 		}
 	}
 
-	// Remove spaces (handles "A 001" -> "A001")
-	location = strings.ReplaceAll(location, " ", "")
-	location = strings.TrimSpace(location)
-
-	// Extract only valid room number: Letter + exactly 3 digits (4 characters total)
-	var roomNumber strings.Builder
-	digitCount := 0
-
-	for i, c := range location {
-		if i == 0 {
-			// First character must be a letter
-			if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
-				roomNumber.WriteRune(c)
-			} else {
-				return "" // Invalid start
-			}
-		} else if c >= '0' && c <= '9' {
-			// Accept digits (exactly 3 expected)
-			roomNumber.WriteRune(c)
-			digitCount++
-			// Stop after 3 digits - no trailing letters allowed
-			if digitCount == 3 {
-				break
-			}
-		} else {
-			// Stop at any other character (including letters after the initial letter)
-			break
-		}
+	if err := tx.Where("timetable_id IN ?", ids).Delete(&models.TimetableRoom{}).Error; err != nil {
+		return fmt.Errorf("failed to delete room assignments: %w", err)
 	}
-
-	result := strings.ToUpper(roomNumber.String())
-
-	// Validate: Must have exactly 4 characters (letter + 3 digits)
-	if len(result) == 4 && digitCount == 3 {
-		return result
+	if len(links) == 0 {
+		return nil
 	}
-
-	return ""
-}
-
-// extractBuildingAndFloor extracts building and floor from room number
-// Example: "A104" -> Building: "A", Floor: "1"
-func extractBuildingAndFloor(roomNumber string) (string, string) {
-	// Clean up the room number first
-	roomNumber = strings.ReplaceAll(roomNumber, "\\", "")
-	roomNumber = strings.TrimSpace(roomNumber)
-
-	if len(roomNumber) < 2 {
-		return "", ""
+	if err := tx.CreateInBatches(&links, timetableBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to save %d room assignments: %w", len(links), err)
 	}
-
-	// Building is the first letter
-	building := strings.TrimSpace(string(roomNumber[0]))
-
-	// Floor is typically the first digit after the letter
-	floor := ""
-	for i := 1; i < len(roomNumber); i++ {
-		if roomNumber[i] >= '0' && roomNumber[i] <= '9' {
-			floor = strings.TrimSpace(string(roomNumber[i]))
-			break
-		}
-	}
-
-	return building, floor
+	return nil
 }
 
 // cleanupSummary cleans up the summary field
@@ -859,10 +1325,18 @@ func cleanupSummary(summary string) string {
 	return summary
 }
 
-// hasChangesDetailed compares two Timetable objects with optional detailed logging
-func hasChangesDetailed(existing, new *models.Timetable, enableLogging bool) bool {
-	changed := false
-	var reasons []string
+// fieldChange describes one differing field between a stored and an imported timetable event
+type fieldChange struct {
+	Field    string // e.g. "room", "start_time"
+	OldValue *string
+	NewValue *string
+	Reason   string // Human-readable description for logging
+}
+
+// diffTimetable returns all fields that differ between existing and new
+// Room and course values are IDs here, changeRecorder resolves them to room numbers/course names
+func diffTimetable(existing, new *models.Timetable) []fieldChange {
+	var changes []fieldChange
 
 	// Compare times (most important for updates)
 	// Truncate to seconds to avoid nanosecond differences
@@ -872,69 +1346,154 @@ func hasChangesDetailed(existing, new *models.Timetable, enableLogging bool) boo
 	newEnd := new.EndTime.Truncate(time.Second)
 
 	if !existingStart.Equal(newStart) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("StartTime: %v -> %v (diff: %v)", existingStart, newStart, newStart.Sub(existingStart)))
+		changes = append(changes, fieldChange{
+			Field:    "start_time",
+			OldValue: timeValue(existingStart),
+			NewValue: timeValue(newStart),
+			Reason:   fmt.Sprintf("StartTime: %v -> %v (diff: %v)", existingStart, newStart, newStart.Sub(existingStart)),
+		})
 	}
 	if !existingEnd.Equal(newEnd) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("EndTime: %v -> %v (diff: %v)", existingEnd, newEnd, newEnd.Sub(existingEnd)))
+		changes = append(changes, fieldChange{
+			Field:    "end_time",
+			OldValue: timeValue(existingEnd),
+			NewValue: timeValue(newEnd),
+			Reason:   fmt.Sprintf("EndTime: %v -> %v (diff: %v)", existingEnd, newEnd, newEnd.Sub(existingEnd)),
+		})
+	}
+
+	if existing.IsAllDay != new.IsAllDay {
+		changes = append(changes, fieldChange{
+			Field:    "all_day",
+			OldValue: boolValue(existing.IsAllDay),
+			NewValue: boolValue(new.IsAllDay),
+			Reason:   fmt.Sprintf("IsAllDay: %v -> %v", existing.IsAllDay, new.IsAllDay),
+		})
 	}
 
 	// Compare basic fields
 	if existing.ZenturienID != new.ZenturienID {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("ZenturienID: %d -> %d", existing.ZenturienID, new.ZenturienID))
+		changes = append(changes, fieldChange{
+			Field:    "zenturie",
+			OldValue: uintValue(&existing.ZenturienID),
+			NewValue: uintValue(&new.ZenturienID),
+			Reason:   fmt.Sprintf("ZenturienID: %d -> %d", existing.ZenturienID, new.ZenturienID),
+		})
 	}
 	if existing.Summary != new.Summary {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("Summary: '%s' -> '%s'", existing.Summary, new.Summary))
+		changes = append(changes, fieldChange{
+			Field:    "summary",
+			OldValue: &existing.Summary,
+			NewValue: &new.Summary,
+			Reason:   fmt.Sprintf("Summary: '%s' -> '%s'", existing.Summary, new.Summary),
+		})
 	}
 
 	// Compare nullable uint pointers (CourseID, RoomID)
 	if !compareNullableUint(existing.CourseID, new.CourseID) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("CourseID: %v -> %v", ptrToString(existing.CourseID), ptrToString(new.CourseID)))
-	}
-	if !compareNullableUint(existing.RoomID, new.RoomID) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("RoomID: %v -> %v", ptrToString(existing.RoomID), ptrToString(new.RoomID)))
+		changes = append(changes, fieldChange{
+			Field:    "course",
+			OldValue: uintValue(existing.CourseID),
+			NewValue: uintValue(new.CourseID),
+			Reason:   fmt.Sprintf("CourseID: %v -> %v", ptrToString(existing.CourseID), ptrToString(new.CourseID)),
+		})
+	}
+	if oldRooms, newRooms := roomIDList(existing), roomIDList(new); oldRooms != newRooms {
+		changes = append(changes, fieldChange{
+			Field:    "room",
+			OldValue: optionalString(oldRooms),
+			NewValue: optionalString(newRooms),
+			Reason:   fmt.Sprintf("Rooms: '%s' -> '%s'", oldRooms, newRooms),
+		})
 	}
 
 	// Compare nullable string pointers
-	if !compareNullableString(existing.Description, new.Description) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("Description: '%s' -> '%s'", ptrToStringStr(existing.Description), ptrToStringStr(new.Description)))
+	stringFields := []struct {
+		field    string
+		label    string
+		old, new *string
+	}{
+		{"description", "Description", existing.Description, new.Description},
+		{"location", "Location", existing.Location, new.Location},
+		{"professor", "Professor", existing.Professor, new.Professor},
+		{"course_type", "CourseType", existing.CourseType, new.CourseType},
+		{"course_code", "CourseCode", existing.CourseCode, new.CourseCode},
+		{"online_link", "OnlineLink", existing.OnlineLink, new.OnlineLink},
+		{"notes", "Notes", existing.Notes, new.Notes},
+		{"source_file", "SourceFile", existing.SourceFile, new.SourceFile},
+	}
+	for _, f := range stringFields {
+		if !compareNullableString(f.old, f.new) {
+			changes = append(changes, fieldChange{
+				Field:    f.field,
+				OldValue: f.old,
+				NewValue: f.new,
+				Reason:   fmt.Sprintf("%s: '%s' -> '%s'", f.label, ptrToStringStr(f.old), ptrToStringStr(f.new)),
+			})
+		}
 	}
-	if !compareNullableString(existing.Location, new.Location) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("Location: '%s' -> '%s'", ptrToStringStr(existing.Location), ptrToStringStr(new.Location)))
+
+	if existing.Status != new.Status {
+		changes = append(changes, fieldChange{
+			Field:    "status",
+			OldValue: &existing.Status,
+			NewValue: &new.Status,
+			Reason:   fmt.Sprintf("Status: '%s' -> '%s'", existing.Status, new.Status),
+		})
 	}
-	if !compareNullableString(existing.Professor, new.Professor) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("Professor: '%s' -> '%s'", ptrToStringStr(existing.Professor), ptrToStringStr(new.Professor)))
+
+	return changes
+}
+
+// roomIDList returns the room IDs of an event in order, e.g. "12, 7"
+// Rows without room assignments (not imported since multi-room support) fall back to RoomID
+func roomIDList(tt *models.Timetable) string {
+	if len(tt.Rooms) == 0 {
+		if tt.RoomID == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*tt.RoomID), 10)
 	}
-	if !compareNullableString(existing.CourseType, new.CourseType) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("CourseType: '%s' -> '%s'", ptrToStringStr(existing.CourseType), ptrToStringStr(new.CourseType)))
+
+	ids := make([]string, len(tt.Rooms))
+	for i, link := range tt.Rooms {
+		ids[i] = strconv.FormatUint(uint64(link.RoomID), 10)
 	}
-	if !compareNullableString(existing.CourseCode, new.CourseCode) {
-		changed = true
-		reasons = append(reasons, fmt.Sprintf("CourseCode: '%s' -> '%s'", ptrToStringStr(existing.CourseCode), ptrToStringStr(new.CourseCode)))
+	return strings.Join(ids, ", ")
+}
+
+// logTimetableChanges logs the result of diffTimetable for one event
+func logTimetableChanges(uid string, changes []fieldChange) {
+	if len(changes) == 0 {
+		log.Printf("  ✓ No changes for UID %s", uid)
+		return
 	}
 
-	// Log changes if requested
-	if enableLogging {
-		if changed && len(reasons) > 0 {
-			log.Printf("  ⚠️ CHANGE DETECTED for UID %s:", existing.UID)
-			for _, reason := range reasons {
-				log.Printf("    - %s", reason)
-			}
-		} else {
-			log.Printf("  ✓ No changes for UID %s", existing.UID)
-		}
+	log.Printf("  ⚠️ CHANGE DETECTED for UID %s:", uid)
+	for _, change := range changes {
+		log.Printf("    - %s", change.Reason)
 	}
+}
+
+// timeValue formats a timestamp for the change history
+func timeValue(t time.Time) *string {
+	value := t.UTC().Format(time.RFC3339)
+	return &value
+}
 
-	return changed
+// boolValue formats a flag for the change history
+func boolValue(b bool) *string {
+	value := strconv.FormatBool(b)
+	return &value
+}
+
+// uintValue formats a nullable ID for the change history
+func uintValue(p *uint) *string {
+	if p == nil {
+		return nil
+	}
+	value := strconv.FormatUint(uint64(*p), 10)
+	return &value
 }
 
 // Helper functions to convert pointers to readable strings
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	ical "github.com/emersion/go-ical"
+	"github.com/nora-nak/backend/config"
+	"github.com/teambition/rrule-go"
+)
+
+// recurrenceReparseInterval is how long an unchanged file with recurring events is skipped
+// before it is parsed again to expand the occurrences that moved into the window
+const recurrenceReparseInterval = 24 * time.Hour
+
+// recurrencePropPattern matches RRULE and RDATE properties of an ICS file
+var recurrencePropPattern = regexp.MustCompile(`(?m)^(RRULE|RDATE)[;:]`)
+
+// containsRecurrence reports whether ICS content has recurring events
+func containsRecurrence(content string) bool {
+	return recurrencePropPattern.MatchString(content)
+}
+
+// recurrenceWindow is the time range recurring events are expanded in
+type recurrenceWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// currentRecurrenceWindow returns the expansion window relative to now
+// (ICS_RECURRENCE_PAST_DAYS / ICS_RECURRENCE_FUTURE_DAYS)
+func currentRecurrenceWindow() recurrenceWindow {
+	pastDays, futureDays := 365, 365
+	if config.AppConfig != nil {
+		pastDays = config.AppConfig.ICSRecurrencePastDays
+		futureDays = config.AppConfig.ICSRecurrenceFutureDays
+	}
+
+	now := time.Now().UTC()
+	return recurrenceWindow{
+		Start: now.AddDate(0, 0, -pastDays),
+		End:   now.AddDate(0, 0, futureDays),
+	}
+}
+
+// parseCalendarEvents parses all VEVENTs of a calendar and expands recurring events
+// - Events without RRULE/RDATE are returned as before
+// - Recurring events produce one event per occurrence within window, EXDATEs are skipped
+// - Overrides (RECURRENCE-ID) replace their occurrence; cancelled overrides remove it
+// Occurrences get the stable UID "<UID>_<occurrence start in UTC>", e.g. "abc_20250113T070000Z".
+// Floating times are interpreted in loc (tenant time zone).
+func parseCalendarEvents(cal *ical.Calendar, window recurrenceWindow, loc *time.Location) []TimetableEvent {
+	tz := newTZResolver(cal, loc)
+
+	var events []TimetableEvent
+	var masters []*ical.Component
+	overrides := make(map[string]*ical.Component) // key: UID + occurrence UID suffix
+	expanded := make(map[string]bool)             // UIDs of masters present in this calendar
+
+	for _, component := range cal.Children {
+		if component.Name != "VEVENT" {
+			continue
+		}
+
+		switch {
+		case component.Props.Get("RECURRENCE-ID") != nil:
+			recurrenceID, err := tz.parseProp(component.Props.Get("RECURRENCE-ID"))
+			if err != nil {
+				log.Printf("WARNING: Invalid RECURRENCE-ID: %v", err)
+				continue
+			}
+			overrides[occurrenceUID(propValue(component, "UID"), recurrenceID.UTC())] = component
+		case component.Props.Get("RRULE") != nil || component.Props.Get("RDATE") != nil:
+			masters = append(masters, component)
+			expanded[propValue(component, "UID")] = true
+		default:
+			if event := parseEvent(component, tz); event != nil {
+				events = append(events, *event)
+			}
+		}
+	}
+
+	for _, master := range masters {
+		occurrences, err := expandRecurrence(master, tz, window, overrides)
+		if err != nil {
+			// Fall back to the first occurrence instead of dropping the event
+			log.Printf("WARNING: Failed to expand recurring event %s: %v", propValue(master, "UID"), err)
+			if event := parseEvent(master, tz); event != nil {
+				events = append(events, *event)
+			}
+			continue
+		}
+		events = append(events, occurrences...)
+	}
+
+	// Overrides without a master in this file are standalone occurrences
+	uids := make([]string, 0, len(overrides))
+	for uid := range overrides {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		override := overrides[uid]
+		if expanded[propValue(override, "UID")] {
+			continue
+		}
+		if isCancelledComponent(override) {
+			continue
+		}
+		if event := parseEvent(override, tz); event != nil {
+			event.UID = uid
+			events = append(events, *event)
+		}
+	}
+
+	return events
+}
+
+// expandRecurrence expands a recurring VEVENT into its occurrences within window
+// The rule is expanded in wall-clock time of DTSTART's zone, so occurrences keep
+// their local time across DST changes. Consumed overrides are removed from the overrides map.
+func expandRecurrence(master *ical.Component, tz *tzResolver, window recurrenceWindow, overrides map[string]*ical.Component) ([]TimetableEvent, error) {
+	startProp := master.Props.Get("DTSTART")
+	if startProp == nil {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+	start, err := tz.parseProp(startProp)
+	if err != nil {
+		return nil, err
+	}
+
+	base := parseEvent(master, tz)
+	if base == nil {
+		return nil, fmt.Errorf("invalid event")
+	}
+
+	// Duration in wall-clock time (all-day events span whole local days)
+	duration := base.EndTime.Sub(base.StartTime)
+	if endProp := master.Props.Get("DTEND"); endProp != nil {
+		if end, err := tz.parseProp(endProp); err == nil && end.Zone == start.Zone {
+			duration = end.Wall.Sub(start.Wall)
+		}
+	}
+	if duration < 0 {
+		duration = 0
+	}
+
+	zone := start.Zone
+	dtstart := start.Wall
+
+	set := &rrule.Set{}
+	if prop := master.Props.Get("RRULE"); prop != nil {
+		option, err := rrule.StrToROptionInLocation(prop.Value, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q: %w", prop.Value, err)
+		}
+		option.Dtstart = dtstart
+		// UNTIL in UTC must be compared in wall-clock time of DTSTART
+		if !option.Until.IsZero() && zone != nil && rruleUntilIsUTC(prop.Value) {
+			option.Until = zone.fromUTC(option.Until)
+		}
+		rule, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q: %w", prop.Value, err)
+		}
+		set.RRule(rule)
+	}
+	set.DTStart(dtstart)
+
+	// DTSTART is always the first occurrence (RFC 5545 3.8.5.3)
+	set.RDate(dtstart)
+	for _, t := range tz.parseProps(master, "RDATE") {
+		set.RDate(wallTimeIn(t, zone))
+	}
+	for _, t := range tz.parseProps(master, "EXDATE") {
+		set.ExDate(wallTimeIn(t, zone))
+	}
+
+	// Window bounds are absolute, the set is in wall-clock time (widen by a day for the offset)
+	after := window.Start.AddDate(0, 0, -1)
+	before := window.End.AddDate(0, 0, 1)
+
+	var events []TimetableEvent
+	for _, wall := range set.Between(after, before, true) {
+		occurrenceStart := icsTime{Wall: wall, Zone: zone}.UTC()
+		if occurrenceStart.Before(window.Start) || occurrenceStart.After(window.End) {
+			continue
+		}
+		uid := occurrenceUID(base.UID, occurrenceStart)
+
+		if override, ok := overrides[uid]; ok {
+			delete(overrides, uid)
+			if isCancelledComponent(override) {
+				continue
+			}
+			if event := parseEvent(override, tz); event != nil {
+				event.UID = uid
+				events = append(events, *event)
+			}
+			continue
+		}
+
+		event := *base
+		event.UID = uid
+		event.StartTime = occurrenceStart
+		event.EndTime = icsTime{Wall: wall.Add(duration), Zone: zone}.UTC()
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// wallTimeIn converts a parsed time to wall-clock time of zone (nil = UTC)
+func wallTimeIn(t icsTime, zone wallClockZone) time.Time {
+	if zone == nil {
+		return t.UTC()
+	}
+	if t.Zone == zone {
+		return t.Wall
+	}
+	return zone.fromUTC(t.UTC())
+}
+
+// rruleUntilIsUTC reports whether the UNTIL part of an RRULE is a UTC time ("...Z")
+func rruleUntilIsUTC(rule string) bool {
+	for _, part := range strings.Split(rule, ";") {
+		if strings.HasPrefix(strings.ToUpper(part), "UNTIL=") {
+			return strings.HasSuffix(strings.ToUpper(part), "Z")
+		}
+	}
+	return false
+}
+
+// occurrenceUID builds the stable UID of one occurrence of a recurring event
+func occurrenceUID(uid string, occurrence time.Time) string {
+	return fmt.Sprintf("%s_%s", uid, occurrence.UTC().Format("20060102T150405Z"))
+}
+
+// propValue returns the value of a property or "" if it is missing
+func propValue(component *ical.Component, name string) string {
+	if prop := component.Props.Get(name); prop != nil {
+		return prop.Value
+	}
+	return ""
+}
+
+// isCancelledComponent reports whether a VEVENT has STATUS:CANCELLED
+func isCancelledComponent(component *ical.Component) bool {
+	return strings.EqualFold(propValue(component, "STATUS"), "CANCELLED")
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	ical "github.com/emersion/go-ical"
+	"github.com/nora-nak/backend/models"
+	"github.com/teambition/rrule-go"
+)
+
+// DefaultTimeZone is used for floating ICS times if the tenant has no time zone configured
+const DefaultTimeZone = "Europe/Berlin"
+
+// wallClockZone converts between wall-clock times of a feed and absolute times
+// Wall-clock times are represented as time.Time values in UTC whose fields are the local time
+type wallClockZone interface {
+	toUTC(wall time.Time) time.Time
+	fromUTC(t time.Time) time.Time
+}
+
+// icsTime is a parsed DATE or DATE-TIME property value
+type icsTime struct {
+	Wall   time.Time     // Wall-clock time as written in the feed
+	Zone   wallClockZone // nil for UTC values ("...Z")
+	AllDay bool          // VALUE=DATE
+}
+
+// UTC returns the absolute time of t
+func (t icsTime) UTC() time.Time {
+	if t.Zone == nil {
+		return t.Wall
+	}
+	return t.Zone.toUTC(t.Wall)
+}
+
+// locationZone is a wallClockZone backed by the IANA time zone database
+type locationZone struct {
+	loc *time.Location
+}
+
+func (z locationZone) toUTC(wall time.Time) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, z.loc).UTC()
+}
+
+func (z locationZone) fromUTC(t time.Time) time.Time {
+	local := t.In(z.loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+}
+
+// vtimezone is a wallClockZone defined by an embedded VTIMEZONE component
+// (used for TZIDs that are not IANA names, e.g. "W. Europe Standard Time" from Outlook)
+type vtimezone struct {
+	observances []tzObservance
+}
+
+// tzObservance is a STANDARD or DAYLIGHT sub-component
+type tzObservance struct {
+	start      time.Time    // DTSTART (wall-clock time before the transition)
+	rule       *rrule.RRule // Optional RRULE for repeating transitions
+	rdates     []time.Time
+	offsetFrom time.Duration
+	offsetTo   time.Duration
+}
+
+// offsetAt returns the UTC offset in effect at a wall-clock time
+func (z *vtimezone) offsetAt(wall time.Time) time.Duration {
+	var latest time.Time
+	var offset time.Duration
+	found := false
+
+	for _, o := range z.observances {
+		onset := o.lastOnset(wall)
+		if onset.IsZero() {
+			continue
+		}
+		if !found || onset.After(latest) {
+			latest = onset
+			offset = o.offsetTo
+			found = true
+		}
+	}
+
+	if !found && len(z.observances) > 0 {
+		// Before the first transition: offset the first observance switched away from
+		return z.observances[0].offsetFrom
+	}
+	return offset
+}
+
+func (z *vtimezone) toUTC(wall time.Time) time.Time {
+	return wall.Add(-z.offsetAt(wall))
+}
+
+func (z *vtimezone) fromUTC(t time.Time) time.Time {
+	// Good enough around transitions: use the offset at the approximated wall time
+	guess := t.Add(z.offsetAt(t))
+	return t.Add(z.offsetAt(guess))
+}
+
+// lastOnset returns the last transition of the observance at or before wall, or zero
+func (o tzObservance) lastOnset(wall time.Time) time.Time {
+	var last time.Time
+	if o.rule != nil {
+		last = o.rule.Before(wall, true)
+	} else if !o.start.After(wall) {
+		last = o.start
+	}
+	for _, rdate := range o.rdates {
+		if !rdate.After(wall) && rdate.After(last) {
+			last = rdate
+		}
+	}
+	return last
+}
+
+// tzResolver parses ICS date values of one calendar, honouring TZID and VTIMEZONE
+type tzResolver struct {
+	fallback  wallClockZone            // Floating times and unknown TZIDs (tenant time zone)
+	zones     map[string]wallClockZone // Resolved TZIDs
+	vtimezone map[string]*ical.Component
+}
+
+// newTZResolver creates a resolver for a calendar; fallback is used for floating times
+func newTZResolver(cal *ical.Calendar, fallback *time.Location) *tzResolver {
+	if fallback == nil {
+		fallback = time.UTC
+	}
+
+	r := &tzResolver{
+		fallback:  locationZone{loc: fallback},
+		zones:     make(map[string]wallClockZone),
+		vtimezone: make(map[string]*ical.Component),
+	}
+
+	if cal != nil {
+		for _, component := range cal.Children {
+			if component.Name == ical.CompTimezone {
+				r.vtimezone[propValue(component, ical.PropTimezoneID)] = component
+			}
+		}
+	}
+
+	return r
+}
+
+// parseProp parses a DATE or DATE-TIME property (DTSTART, DTEND, RECURRENCE-ID)
+func (r *tzResolver) parseProp(prop *ical.Prop) (icsTime, error) {
+	return r.parseValue(prop.Value, prop.Params)
+}
+
+// parseProps parses all values of a multi-valued date property (RDATE, EXDATE)
+func (r *tzResolver) parseProps(component *ical.Component, name string) []icsTime {
+	var times []icsTime
+	for _, prop := range component.Props.Values(name) {
+		for _, value := range strings.Split(prop.Value, ",") {
+			t, err := r.parseValue(strings.TrimSpace(value), prop.Params)
+			if err != nil {
+				log.Printf("WARNING: Invalid %s value %q: %v", name, value, err)
+				continue
+			}
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// parseValue parses an ICS date value with the property's parameters
+func (r *tzResolver) parseValue(value string, params ical.Params) (icsTime, error) {
+	// Date only format (VALUE=DATE): all-day, anchored at local midnight
+	if len(value) == 8 || strings.EqualFold(params.Get(ical.ParamValue), string(ical.ValueDate)) {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return icsTime{}, fmt.Errorf("unable to parse date: %s", value)
+		}
+		return icsTime{Wall: t, Zone: r.zone(params.Get(ical.ParamTimezoneID)), AllDay: true}, nil
+	}
+
+	// Format with Z suffix is already UTC
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return icsTime{}, fmt.Errorf("unable to parse time: %s", value)
+		}
+		return icsTime{Wall: t.UTC()}, nil
+	}
+
+	// Local time: TZID parameter, otherwise floating (tenant time zone)
+	t, err := time.Parse("20060102T150405", value)
+	if err != nil {
+		return icsTime{}, fmt.Errorf("unable to parse time: %s", value)
+	}
+	return icsTime{Wall: t, Zone: r.zone(params.Get(ical.ParamTimezoneID))}, nil
+}
+
+// zone resolves a TZID: IANA name, embedded VTIMEZONE, or the fallback zone
+func (r *tzResolver) zone(tzid string) wallClockZone {
+	if tzid == "" {
+		return r.fallback
+	}
+	if zone, ok := r.zones[tzid]; ok {
+		return zone
+	}
+
+	zone := r.resolve(tzid)
+	r.zones[tzid] = zone
+	return zone
+}
+
+func (r *tzResolver) resolve(tzid string) wallClockZone {
+	// IANA names, also as suffix of vendor prefixes like "/mozilla.org/20050126_1/Europe/Berlin"
+	if loc := loadIANALocation(tzid); loc != nil {
+		return locationZone{loc: loc}
+	}
+
+	if component, ok := r.vtimezone[tzid]; ok {
+		// Some producers name the IANA zone explicitly
+		if loc := loadIANALocation(propValue(component, "X-LIC-LOCATION")); loc != nil {
+			return locationZone{loc: loc}
+		}
+		zone, err := parseVTimezone(component)
+		if err == nil {
+			return zone
+		}
+		log.Printf("WARNING: Invalid VTIMEZONE %s: %v", tzid, err)
+	}
+
+	log.Printf("WARNING: Unknown TZID %q, using tenant time zone", tzid)
+	return r.fallback
+}
+
+// loadIANALocation loads an IANA zone, trying path suffixes of vendor-prefixed TZIDs
+func loadIANALocation(name string) *time.Location {
+	name = strings.Trim(name, "\"")
+	if name == "" {
+		return nil
+	}
+
+	parts := strings.Split(strings.Trim(name, "/"), "/")
+	for i := 0; i < len(parts); i++ {
+		candidate := strings.Join(parts[i:], "/")
+		if candidate == "" || strings.EqualFold(candidate, "local") {
+			continue
+		}
+		if loc, err := time.LoadLocation(candidate); err == nil {
+			return loc
+		}
+	}
+	return nil
+}
+
+// parseVTimezone builds a wallClockZone from the STANDARD/DAYLIGHT sub-components of a VTIMEZONE
+func parseVTimezone(component *ical.Component) (*vtimezone, error) {
+	zone := &vtimezone{}
+
+	for _, child := range component.Children {
+		if child.Name != ical.CompTimezoneStandard && child.Name != ical.CompTimezoneDaylight {
+			continue
+		}
+
+		start, err := time.Parse("20060102T150405", propValue(child, ical.PropDateTimeStart))
+		if err != nil {
+			return nil, fmt.Errorf("invalid DTSTART in %s", child.Name)
+		}
+		offsetFrom, err := parseUTCOffset(propValue(child, ical.PropTimezoneOffsetFrom))
+		if err != nil {
+			return nil, err
+		}
+		offsetTo, err := parseUTCOffset(propValue(child, ical.PropTimezoneOffsetTo))
+		if err != nil {
+			return nil, err
+		}
+
+		observance := tzObservance{start: start, offsetFrom: offsetFrom, offsetTo: offsetTo}
+
+		if value := propValue(child, ical.PropRecurrenceRule); value != "" {
+			option, err := rrule.StrToROption(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE in %s: %w", child.Name, err)
+			}
+			option.Dtstart = start
+			if !option.Until.IsZero() {
+				// UNTIL is given in UTC, transitions are compared in wall-clock time
+				option.Until = option.Until.Add(offsetFrom)
+			}
+			if observance.rule, err = rrule.NewRRule(*option); err != nil {
+				return nil, fmt.Errorf("invalid RRULE in %s: %w", child.Name, err)
+			}
+		}
+
+		for _, prop := range child.Props.Values(ical.PropRecurrenceDates) {
+			for _, value := range strings.Split(prop.Value, ",") {
+				if t, err := time.Parse("20060102T150405", strings.TrimSpace(value)); err == nil {
+					observance.rdates = append(observance.rdates, t)
+				}
+			}
+		}
+
+		zone.observances = append(zone.observances, observance)
+	}
+
+	if len(zone.observances) == 0 {
+		return nil, fmt.Errorf("no STANDARD or DAYLIGHT definitions")
+	}
+
+	return zone, nil
+}
+
+// parseUTCOffset parses "+0100", "-0500" or "+053000"
+func parseUTCOffset(value string) (time.Duration, error) {
+	if len(value) != 5 && len(value) != 7 {
+		return 0, fmt.Errorf("invalid UTC offset: %q", value)
+	}
+
+	sign := time.Duration(1)
+	switch value[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return 0, fmt.Errorf("invalid UTC offset: %q", value)
+	}
+
+	hours, err1 := strconv.Atoi(value[1:3])
+	minutes, err2 := strconv.Atoi(value[3:5])
+	seconds := 0
+	var err3 error
+	if len(value) == 7 {
+		seconds, err3 = strconv.Atoi(value[5:7])
+	}
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, fmt.Errorf("invalid UTC offset: %q", value)
+	}
+
+	return sign * (time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second), nil
+}
+
+// TenantLocation returns the time zone of a tenant (Europe/Berlin if unset or invalid)
+func TenantLocation(tenant *models.Tenant) *time.Location {
+	name := DefaultTimeZone
+	if tenant != nil && tenant.TimeZone != "" {
+		name = tenant.TimeZone
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("WARNING: Invalid time zone %q for tenant, using %s: %v", name, DefaultTimeZone, err)
+		if loc, err = time.LoadLocation(DefaultTimeZone); err != nil {
+			return time.UTC
+		}
+	}
+	return loc
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+)
+
+// Errors returned by ApproveQuarantine and RejectQuarantine
+var (
+	ErrQuarantineNotFound     = errors.New("quarantined file not found")
+	ErrQuarantineNotPending   = errors.New("quarantined file was already reviewed")
+	ErrQuarantineImportFailed = errors.New("quarantined file could not be imported")
+)
+
+// guardMinEvents is the number of upcoming active events a file needs before the drop guard applies
+// Small files (e.g. a semester with a few exams) legitimately shrink by more than half
+const guardMinEvents = 10
+
+// ImportGuards are the sanity limits of an import, 0 disables a guard
+// A source file violating a guard is quarantined instead of imported. A file without any
+// upcoming events that replaces upcoming active events is always quarantined.
+type ImportGuards struct {
+	MaxDropPercent int // Max. drop of a file's active event count in percent
+	MaxMovedEvents int // Max. number of existing events moved to another time
+}
+
+// QuarantinedFile is a source file an import held back
+type QuarantinedFile struct {
+	ID         uint
+	Zenturie   string
+	SourceFile string
+	Reasons    []string
+	New        bool // False if the same file was already quarantined before
+}
+
+// guardResult are the numbers the import guards are checked against
+type guardResult struct {
+	Previous int // Upcoming active events of the file before the import
+	Current  int // Upcoming valid events in the fetched file
+	Moved    int // Existing events with a different start or end time
+	Reasons  []string
+}
+
+// check compares the events of a source file with the stored events of the zenturie
+// Event counts only include events that did not end yet, the same scope cancelVanishedEvents
+// uses: feeds may drop past events and recurring events are only expanded around now.
+func (g *ImportGuards) check(sourceFile string, events []TimetableEvent, existing map[string]models.Timetable) guardResult {
+	var result guardResult
+	now := time.Now()
+	for _, tt := range existing {
+		if tt.SourceFile != nil && *tt.SourceFile == sourceFile && tt.Status == models.TimetableStatusActive && !tt.EndTime.Before(now) {
+			result.Previous++
+		}
+	}
+
+	for _, event := range dedupeEvents(events) {
+		if event.UID == "" || event.Summary == "" {
+			continue
+		}
+		if !event.EndTime.Before(now) {
+			result.Current++
+		}
+
+		old, ok := existing[event.UID]
+		if ok && old.Status == models.TimetableStatusActive &&
+			(!old.StartTime.Equal(event.StartTime) || !old.EndTime.Equal(event.EndTime)) {
+			result.Moved++
+		}
+	}
+
+	if result.Current == 0 && result.Previous > 0 {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("file contains no upcoming events (%d upcoming events before)", result.Previous))
+	} else if g.MaxDropPercent > 0 && result.Previous >= guardMinEvents && result.Current < result.Previous {
+		drop := (result.Previous - result.Current) * 100 / result.Previous
+		if drop > g.MaxDropPercent {
+			result.Reasons = append(result.Reasons, fmt.Sprintf("event count dropped by %d%% (%d -> %d, limit %d%%)",
+				drop, result.Previous, result.Current, g.MaxDropPercent))
+		}
+	}
+	if g.MaxMovedEvents > 0 && result.Moved > g.MaxMovedEvents {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("%d events moved to another time (limit %d)",
+			result.Moved, g.MaxMovedEvents))
+	}
+
+	return result
+}
+
+// quarantine stores a source file that violates an import guard
+// The same file content is quarantined only once while pending or rejected, older pending
+// versions of the file are superseded by the new one.
+func (imp *eventImporter) quarantine(zenturie *models.Zenturie, sourceFile string, events []TimetableEvent, guard guardResult) (*QuarantinedFile, error) {
+	tenantID := imp.opts.TenantID
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode events: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	file := &QuarantinedFile{
+		Zenturie:   zenturie.Name,
+		SourceFile: sourceFile,
+		Reasons:    guard.Reasons,
+	}
+
+	var existing models.ImportQuarantine
+	err = imp.db.Where("tenant_id = ? AND zenturie = ? AND source_file = ? AND content_hash = ? AND status IN ?",
+		tenantID, zenturie.Name, sourceFile, hash,
+		[]string{models.QuarantineStatusPending, models.QuarantineStatusRejected}).
+		First(&existing).Error
+	if err == nil {
+		file.ID = existing.ID
+		return file, nil
+	}
+
+	record := models.ImportQuarantine{
+		TenantID:       tenantID,
+		ImportRunID:    imp.opts.RunID,
+		Zenturie:       zenturie.Name,
+		SourceFile:     sourceFile,
+		Status:         models.QuarantineStatusPending,
+		Reasons:        strings.Join(guard.Reasons, "\n"),
+		PreviousEvents: guard.Previous,
+		NewEvents:      guard.Current,
+		MovedEvents:    guard.Moved,
+		ContentHash:    hash,
+		Events:         string(data),
+	}
+
+	err = imp.db.Transaction(func(tx *gorm.DB) error {
+		if err := supersedeQuarantines(tx, tenantID, zenturie.Name, sourceFile); err != nil {
+			return err
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save quarantined file: %w", err)
+	}
+
+	file.ID = record.ID
+	file.New = true
+	return file, nil
+}
+
+// supersedeQuarantines marks the pending quarantined versions of a source file as superseded
+func supersedeQuarantines(db *gorm.DB, tenantID uint, zenturie, sourceFile string) error {
+	return db.Model(&models.ImportQuarantine{}).
+		Where("tenant_id = ? AND zenturie = ? AND source_file = ? AND status = ?",
+			tenantID, zenturie, sourceFile, models.QuarantineStatusPending).
+		Update("status", models.QuarantineStatusSuperseded).Error
+}
+
+// ApproveQuarantine imports a quarantined file without guards and marks it as approved
+// The import is recorded as an import run with the "approval" trigger.
+func ApproveQuarantine(id uint, reviewerID *uint) (*TenantImportResult, error) {
+	record, err := loadPendingQuarantine(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []TimetableEvent
+	if err := json.Unmarshal([]byte(record.Events), &events); err != nil {
+		return nil, fmt.Errorf("failed to decode quarantined events: %w", err)
+	}
+
+	log.Printf("Importing approved quarantined file %s of zenturie %s", record.SourceFile, record.Zenturie)
+
+	result, err := runFileImport(record.TenantID, models.ImportTriggerApproval, record.Zenturie, record.SourceFile, events, false)
+	if err != nil {
+		return result, err
+	}
+	// A rolled back file stays pending, so it can be approved again
+	// (skipped invalid events also count as errors, but the rest of the file is imported)
+	if result.Stats != nil && fileRolledBack(result.Stats, record.Zenturie, record.SourceFile) {
+		return result, fmt.Errorf("%w: import rolled back", ErrQuarantineImportFailed)
+	}
+
+	if err := reviewQuarantine(record, models.QuarantineStatusApproved, reviewerID); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// fileRolledBack reports whether the importer rolled back or could not import a source file
+func fileRolledBack(stats *ImportStatistics, zenturie, sourceFile string) bool {
+	for _, zs := range stats.Zenturien {
+		if zs.Zenturie != zenturie {
+			continue
+		}
+		for _, failed := range zs.FailedFiles {
+			if failed == sourceFile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RejectQuarantine discards a quarantined file, the same file content is not quarantined again
+func RejectQuarantine(id uint, reviewerID *uint) error {
+	record, err := loadPendingQuarantine(id)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Rejected quarantined file %s of zenturie %s", record.SourceFile, record.Zenturie)
+	return reviewQuarantine(record, models.QuarantineStatusRejected, reviewerID)
+}
+
+// loadPendingQuarantine loads a quarantined file that still awaits review
+func loadPendingQuarantine(id uint) (*models.ImportQuarantine, error) {
+	var record models.ImportQuarantine
+	if err := config.DB.First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuarantineNotFound
+		}
+		return nil, fmt.Errorf("failed to load quarantined file: %w", err)
+	}
+	if record.Status != models.QuarantineStatusPending {
+		return nil, ErrQuarantineNotPending
+	}
+	return &record, nil
+}
+
+// reviewQuarantine stores the review decision of a quarantined file
+func reviewQuarantine(record *models.ImportQuarantine, status string, reviewerID *uint) error {
+	now := time.Now().UTC()
+	record.Status = status
+	record.ReviewedBy = reviewerID
+	record.ReviewedAt = &now
+
+	if err := config.DB.Model(record).Updates(map[string]interface{}{
+		"status":      status,
+		"reviewed_by": reviewerID,
+		"reviewed_at": now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update quarantined file: %w", err)
+	}
+	return nil
+}
+
+// quarantineSummary returns one line per newly quarantined file for the notification email
+func quarantineSummary(files []QuarantinedFile) []string {
+	var lines []string
+	for _, file := range files {
+		if !file.New {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s/%s: %s", file.Zenturie, file.SourceFile, strings.Join(file.Reasons, "; ")))
+	}
+	return lines
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+)
+
+// ImportOptions controls a single ImportEventsToDatabase call
+type ImportOptions struct {
+	TenantID uint
+	RunID    *uint         // Import run the recorded timetable changes belong to
+	DryRun   bool          // Roll back all writes and return the changes as ImportStatistics.Preview
+	Guards   *ImportGuards // Quarantine source files that violate a guard, nil imports every file
+
+	// Zenturie -> source files fetched in this run, files without events are imported as empty
+	// (their events are cancelled unless a guard quarantines the file)
+	SourceFiles map[string][]string
+}
+
+// ImportPreview lists the changes a dry-run import would make
+type ImportPreview struct {
+	Created   []PreviewEvent `json:"created"`
+	Updated   []PreviewEvent `json:"updated"`
+	Cancelled []PreviewEvent `json:"cancelled"` // Removed from the feed
+}
+
+// PreviewEvent is one event of an ImportPreview
+type PreviewEvent struct {
+	Zenturie  string               `json:"zenturie"`
+	UID       string               `json:"uid"`
+	Summary   string               `json:"summary"`
+	StartTime time.Time            `json:"start_time"`
+	EndTime   time.Time            `json:"end_time"`
+	Changes   []PreviewFieldChange `json:"changes,omitempty"` // Updated events only
+}
+
+// PreviewFieldChange is one changed field of an updated PreviewEvent
+type PreviewFieldChange struct {
+	Field    string  `json:"field"`
+	OldValue *string `json:"old_value,omitempty"`
+	NewValue *string `json:"new_value,omitempty"`
+}
+
+// newImportPreview creates an empty preview (empty lists instead of null in JSON)
+func newImportPreview() *ImportPreview {
+	return &ImportPreview{
+		Created:   []PreviewEvent{},
+		Updated:   []PreviewEvent{},
+		Cancelled: []PreviewEvent{},
+	}
+}
+
+// previewEvent builds the PreviewEvent of a timetable row
+func previewEvent(zenturie string, tt *models.Timetable) PreviewEvent {
+	return PreviewEvent{
+		Zenturie:  zenturie,
+		UID:       tt.UID,
+		Summary:   tt.Summary,
+		StartTime: tt.StartTime,
+		EndTime:   tt.EndTime,
+	}
+}
+
+// startImportRun records the start of an import run for a tenant
+// Returns nil if the run could not be stored; the import still runs without history
+func startImportRun(tenantID uint, trigger string) *models.ImportRun {
+	run := models.ImportRun{
+		TenantID:  tenantID,
+		Trigger:   trigger,
+		Status:    models.ImportRunStatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := config.DB.Create(&run).Error; err != nil {
+		log.Printf("WARNING: Failed to create import run for tenant %d: %v", tenantID, err)
+		return nil
+	}
+	return &run
+}
+
+// finishImportRun stores the outcome of an import run with its statistics and per-file results
+// stats is nil if the import failed before the database step
+func finishImportRun(run *models.ImportRun, stats *ImportStatistics, files []models.ImportRunFileResult, importErr error) {
+	if run == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	duration := now.Sub(run.StartedAt).Milliseconds()
+	run.FinishedAt = &now
+	run.DurationMs = &duration
+	run.Status = ImportRunStatus(stats, importErr)
+	if importErr != nil {
+		message := importErr.Error()
+		run.Error = &message
+	}
+
+	for i := range files {
+		files[i].ImportRunID = run.ID
+		if files[i].Status == models.ImportFileFailed {
+			run.FilesFailed++
+		}
+	}
+
+	if stats != nil {
+		run.FilesDownloaded = stats.FilesDownloaded
+		run.FilesSkippedUnchanged = stats.FilesSkippedUnchanged
+		run.EventsCreated = stats.EventsCreated
+		run.EventsUpdated = stats.EventsUpdated
+		run.EventsUnchanged = stats.EventsUnchanged
+		run.EventsCancelled = stats.EventsCancelled
+		run.FilesQuarantined = len(stats.Quarantined)
+		run.Errors = stats.Errors
+	} else if importErr != nil {
+		run.Errors = 1
+	}
+
+	if err := config.DB.Save(run).Error; err != nil {
+		log.Printf("WARNING: Failed to update import run %d: %v", run.ID, err)
+		return
+	}
+
+	if stats != nil && len(stats.Zenturien) > 0 {
+		results := make([]models.ImportRunZenturieResult, 0, len(stats.Zenturien))
+		for _, z := range stats.Zenturien {
+			results = append(results, models.ImportRunZenturieResult{
+				ImportRunID: run.ID,
+				Zenturie:    z.Zenturie,
+				Created:     z.Created,
+				Updated:     z.Updated,
+				Unchanged:   z.Unchanged,
+				Cancelled:   z.Cancelled,
+				Errors:      z.Errors,
+			})
+		}
+		if err := config.DB.Create(&results).Error; err != nil {
+			log.Printf("WARNING: Failed to save zenturie results of import run %d: %v", run.ID, err)
+		}
+	}
+
+	if len(files) > 0 {
+		if err := config.DB.Create(&files).Error; err != nil {
+			log.Printf("WARNING: Failed to save file results of import run %d: %v", run.ID, err)
+		}
+	}
+}
+
+// buildFileResults builds the per-file results of an import run from the fetched files
+// events (may be nil if parsing failed) is used to count the events parsed per file
+func buildFileResults(icsData []ICSData, events map[string][]TimetableEvent) []models.ImportRunFileResult {
+	parsed := make(map[string]int)
+	for zenturie, zenturieEvents := range events {
+		for _, event := range zenturieEvents {
+			parsed[zenturie+"/"+event.SourceFile]++
+		}
+	}
+
+	results := make([]models.ImportRunFileResult, 0, len(icsData))
+	for _, data := range icsData {
+		result := models.ImportRunFileResult{
+			Zenturie:     data.Zenturie,
+			SourceFile:   data.SourceFile,
+			URL:          data.URL,
+			Status:       models.ImportFileDownloaded,
+			EventsParsed: parsed[data.Zenturie+"/"+data.SourceFile],
+		}
+		switch {
+		case data.Error != "":
+			message := data.Error
+			result.Status = models.ImportFileFailed
+			result.Error = &message
+		case data.Unchanged:
+			result.Status = models.ImportFileUnchanged
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// runFileImport imports the events of a single source file outside the fetch pipeline
+// (approved quarantines, snapshot replays). Fails with ErrImportInProgress while another import
+// runs on any instance.
+func runFileImport(tenantID uint, trigger, zenturie, sourceFile string, events []TimetableEvent, dryRun bool) (*TenantImportResult, error) {
+	if !importMu.TryLock() {
+		return nil, ErrImportInProgress
+	}
+	defer importMu.Unlock()
+
+	release, err := tryImportLock(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var run *models.ImportRun
+	if !dryRun {
+		run = startImportRun(tenantID, trigger)
+	}
+	result := &TenantImportResult{}
+	if run != nil {
+		result.RunID = &run.ID
+	}
+
+	result.Stats, err = ImportEventsToDatabase(ImportOptions{
+		TenantID:    tenantID,
+		RunID:       result.RunID,
+		DryRun:      dryRun,
+		SourceFiles: map[string][]string{zenturie: {sourceFile}},
+	}, map[string][]TimetableEvent{zenturie: events})
+	result.Files = []models.ImportRunFileResult{{
+		Zenturie:     zenturie,
+		SourceFile:   sourceFile,
+		Status:       models.ImportFileDownloaded,
+		EventsParsed: len(events),
+	}}
+	if result.Stats != nil {
+		markFailedFiles(result.Files, result.Stats.Zenturien)
+	}
+	finishImportRun(run, result.Stats, result.Files, err)
+	return result, err
+}
+
+// markFailedFiles marks the per-file results of files the importer rolled back
+func markFailedFiles(files []models.ImportRunFileResult, zenturien []ZenturieStatistics) {
+	for _, zs := range zenturien {
+		for _, sourceFile := range zs.FailedFiles {
+			for i := range files {
+				if files[i].Zenturie == zs.Zenturie && files[i].SourceFile == sourceFile {
+					message := "import rolled back"
+					files[i].Status = models.ImportFileFailed
+					files[i].Error = &message
+				}
+			}
+		}
+	}
+}
+
+// markQuarantinedFiles sets the status of the per-file results that were quarantined
+func markQuarantinedFiles(files []models.ImportRunFileResult, quarantined []QuarantinedFile) {
+	for _, q := range quarantined {
+		for i := range files {
+			if files[i].Zenturie == q.Zenturie && files[i].SourceFile == q.SourceFile {
+				files[i].Status = models.ImportFileQuarantined
+			}
+		}
+	}
+}
+
+// ImportRunStatus returns the status of a finished import
+// Runs with failed downloads or events that could not be imported (including rolled back files) are partial.
+func ImportRunStatus(stats *ImportStatistics, importErr error) string {
+	switch {
+	case importErr != nil:
+		return models.ImportRunStatusFailed
+	case stats != nil && (stats.Errors > 0 || stats.FilesFailed > 0):
+		return models.ImportRunStatusPartial
+	default:
+		return models.ImportRunStatusSuccess
+	}
+}
+
+// GetLastSuccessfulImportRun returns the most recent successful import run of a tenant, or nil
+func GetLastSuccessfulImportRun(tenantID uint) *models.ImportRun {
+	var run models.ImportRun
+	err := config.DB.Where("tenant_id = ? AND status = ?", tenantID, models.ImportRunStatusSuccess).
+		Order("started_at DESC").
+		First(&run).Error
+	if err != nil {
+		return nil
+	}
+	return &run
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strings"
+
+	"github.com/nora-nak/backend/config"
+)
+
+// jobLockNamespace is the first key of all scheduler job advisory locks ("NORA")
+// The second key is derived from the job name, see jobLockKey.
+const jobLockNamespace int32 = 0x4e4f5241
+
+// jobLockKey returns the advisory lock key of a job
+// Kept positive, so it equals the objid column of pg_locks.
+func jobLockKey(name string) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int32(h.Sum32() & 0x7fffffff)
+}
+
+// tryJobLock takes the cluster-wide lock of a job without waiting
+// The lock is held by a dedicated database session until release is called, so it is also
+// freed when the instance dies. Returns ok == false if another session holds the lock.
+func tryJobLock(ctx context.Context, name string) (release func(), ok bool, err error) {
+	sqlDB, err := config.DB.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	key := jobLockKey(name)
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", jobLockNamespace, key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to take lock of job %s: %w", name, err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release = func() {
+		// Not bound to the job's context, a cancelled run must still unlock
+		var unlocked bool
+		if err := conn.QueryRowContext(context.Background(), "SELECT pg_advisory_unlock($1, $2)", jobLockNamespace, key).Scan(&unlocked); err != nil || !unlocked {
+			log.Printf("WARNING: Failed to release lock of job %s: %v", name, err)
+			// Discard the session, ending it releases the lock
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+// withJobLock runs fn while holding the cluster-wide lock of a job
+// Returns ran == false without running fn if another instance holds the lock.
+func withJobLock(ctx context.Context, name string, fn func() error) (ran bool, err error) {
+	release, ok, err := tryJobLock(ctx, name)
+	if err != nil || !ok {
+		return false, err
+	}
+	defer release()
+
+	return true, fn()
+}
+
+// tryImportLock takes the lock of the import job for imports started by an admin
+// Fails with ErrImportInProgress while any instance runs an import.
+func tryImportLock(ctx context.Context) (func(), error) {
+	release, ok, err := tryJobLock(ctx, importJobName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrImportInProgress
+	}
+	return release, nil
+}
+
+// getJobLockHolders returns the instance holding the lock of each running job
+// Instances are identified by the application_name of their database sessions.
+func getJobLockHolders() map[string]string {
+	var rows []struct {
+		ObjID           int64
+		ApplicationName string
+	}
+	err := config.DB.Raw(`SELECT l.objid::bigint AS obj_id, a.application_name
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 2 AND l.classid = ?`,
+		jobLockNamespace).Scan(&rows).Error
+	if err != nil {
+		log.Printf("WARNING: Failed to load job lock holders: %v", err)
+		return nil
+	}
+
+	holders := make(map[string]string)
+	for _, job := range schedulerJobs() {
+		key := int64(jobLockKey(job.Name))
+		for _, row := range rows {
+			if row.ObjID == key {
+				holders[job.Name] = strings.TrimPrefix(row.ApplicationName, config.DBApplicationPrefix)
+			}
+		}
+	}
+	return holders
+}
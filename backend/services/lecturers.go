@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+)
+
+// Errors returned by MergeLecturers
+var (
+	ErrLecturerNotFound = errors.New("lecturer not found")
+	ErrInvalidMerge     = errors.New("invalid lecturer merge")
+)
+
+// lecturerTitles are ignored when comparing lecturer names (compared without dots and hyphens)
+var lecturerTitles = map[string]bool{
+	"prof": true, "professor": true, "dr": true, "dring": true, "drrernat": true, "rer": true, "nat": true,
+	"pol": true, "oec": true, "phil": true, "med": true, "jur": true, "habil": true, "hc": true, "mult": true,
+	"msc": true, "bsc": true, "mba": true, "meng": true, "beng": true, "llm": true,
+	"herr": true, "frau": true,
+}
+
+// NormalizeLecturerName returns the name used to recognize a lecturer across name variants
+// Titles and punctuation are removed and the name is lower-cased: "Prof. Dr. Müller" -> "müller"
+func NormalizeLecturerName(name string) string {
+	var parts []string
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		title := strings.NewReplacer(".", "", "-", "").Replace(word)
+		if lecturerTitles[title] || strings.HasPrefix(title, "dipl") {
+			continue
+		}
+		if word = strings.Trim(word, ".,;:()"); word != "" {
+			parts = append(parts, word)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// findOrCreateLecturer finds or creates the lecturer of a professor field within a tenant
+// Names of merged lecturers resolve to the lecturer they were merged into
+func findOrCreateLecturer(db *gorm.DB, tenantID uint, name string) *uint {
+	name = strings.TrimSpace(name)
+	normalized := NormalizeLecturerName(name)
+	if normalized == "" {
+		return nil
+	}
+
+	var lecturer models.Lecturer
+	result := db.Where("tenant_id = ? AND normalized_name = ?", tenantID, normalized).First(&lecturer)
+
+	if result.Error == nil {
+		if lecturer.MergedIntoID != nil {
+			return lecturer.MergedIntoID
+		}
+
+		// Keep the most complete variant as display name ("Prof. Dr. Müller" instead of "Müller")
+		if len(name) > len(lecturer.Name) {
+			if err := db.Model(&lecturer).Update("name", name).Error; err != nil {
+				log.Printf("WARNING: Failed to update name of lecturer %d: %v", lecturer.ID, err)
+			}
+		}
+		return &lecturer.ID
+	}
+
+	lecturer = models.Lecturer{
+		TenantID:       tenantID,
+		Name:           name,
+		NormalizedName: normalized,
+	}
+
+	if err := db.Create(&lecturer).Error; err != nil {
+		log.Printf("ERROR creating lecturer %s: %v", name, err)
+		return nil
+	}
+
+	log.Printf("Created new lecturer: %s", name)
+	return &lecturer.ID
+}
+
+// MergeLecturers merges the source lecturers of a tenant into target
+// Their events are assigned to target, the sources stay as redirects for future imports.
+func MergeLecturers(tenantID, targetID uint, sourceIDs []uint) error {
+	sourceIDs = slices.Compact(slices.Sorted(slices.Values(sourceIDs)))
+	if len(sourceIDs) == 0 || slices.Contains(sourceIDs, targetID) {
+		return fmt.Errorf("%w: sources must be given and must not contain the target", ErrInvalidMerge)
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var target models.Lecturer
+		if err := tx.Where("tenant_id = ?", tenantID).First(&target, targetID).Error; err != nil {
+			return ErrLecturerNotFound
+		}
+		if target.MergedIntoID != nil {
+			return fmt.Errorf("%w: target %d was merged into lecturer %d", ErrInvalidMerge, target.ID, *target.MergedIntoID)
+		}
+
+		var count int64
+		if err := tx.Model(&models.Lecturer{}).
+			Where("tenant_id = ? AND id IN ? AND merged_into_id IS NULL", tenantID, sourceIDs).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to load lecturers: %w", err)
+		}
+		if int(count) != len(sourceIDs) {
+			return ErrLecturerNotFound
+		}
+
+		if err := tx.Model(&models.Timetable{}).
+			Where("tenant_id = ? AND lecturer_id IN ?", tenantID, sourceIDs).
+			Update("lecturer_id", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to reassign events: %w", err)
+		}
+
+		// Redirects to a source (earlier merges) point to the target directly
+		if err := tx.Model(&models.Lecturer{}).
+			Where("tenant_id = ? AND (id IN ? OR merged_into_id IN ?)", tenantID, sourceIDs, sourceIDs).
+			Update("merged_into_id", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to merge lecturers: %w", err)
+		}
+
+		log.Printf("Merged lecturers %v into %d (%s)", sourceIDs, target.ID, target.Name)
+		return nil
+	})
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"github.com/nora-nak/backend/utils"
+	"gorm.io/gorm"
+)
+
+// ErrRetentionInProgress is returned for manual retention runs while the retention job runs
+var ErrRetentionInProgress = errors.New("retention job is already running")
+
+// Names of the retention jobs
+const (
+	retentionJobName    = "retention"
+	logRetentionJobName = "log_retention"
+)
+
+// rotatedLogPattern matches rotated log files, e.g. backend_logs-2025-01-20.log (optionally with a time suffix)
+var rotatedLogPattern = regexp.MustCompile(`-(\d{4}-\d{2}-\d{2})(-\d{6})?\.log$`)
+
+// RetentionReport is the result of a retention run
+// Dry runs report the data that would be deleted.
+type RetentionReport struct {
+	RunID      *uint                     `json:"retention_run_id,omitempty"`
+	DryRun     bool                      `json:"dry_run"`
+	TotalCount int64                     `json:"total_count"`
+	Items      []models.RetentionRunItem `json:"items"`
+}
+
+// DefaultRetentionPolicy returns the retention periods of tenants without a policy
+func DefaultRetentionPolicy(tenantID uint) models.RetentionPolicy {
+	return models.RetentionPolicy{
+		TenantID:          tenantID,
+		TimetableDays:     365,
+		FriendRequestDays: 30,
+		CustomHourDays:    180,
+	}
+}
+
+// GetRetentionPolicy loads the retention policy of a tenant, or the defaults if none is stored
+func GetRetentionPolicy(tenantID uint) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	if err := config.DB.Where("tenant_id = ?", tenantID).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			policy = DefaultRetentionPolicy(tenantID)
+			return &policy, nil
+		}
+		return nil, fmt.Errorf("failed to load retention policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// SaveRetentionPolicy creates or updates the retention policy of a tenant
+func SaveRetentionPolicy(policy *models.RetentionPolicy) error {
+	if err := config.DB.Save(policy).Error; err != nil {
+		return fmt.Errorf("failed to save retention policy: %w", err)
+	}
+
+	log.Printf("Saved retention policy for tenant %d: timetables %d, friend requests %d, custom hours %d days",
+		policy.TenantID, policy.TimetableDays, policy.FriendRequestDays, policy.CustomHourDays)
+	return nil
+}
+
+// runRetentionJob is the scheduled retention run of the tenants' data
+func runRetentionJob(ctx context.Context) error {
+	_, err := RunRetention(ctx, models.RetentionTriggerCron, false)
+	return err
+}
+
+// runLogRetentionJob is the scheduled cleanup of this instance's log files
+func runLogRetentionJob(ctx context.Context) error {
+	_, err := RunLogRetention(models.RetentionTriggerCron, false)
+	return err
+}
+
+// RunRetentionNow runs the retention of the tenants' data and of this instance's log files, started by an admin
+// Fails with ErrRetentionInProgress while the retention job runs on any instance (dry runs excepted).
+func RunRetentionNow(dryRun bool) (*RetentionReport, error) {
+	mu.Lock()
+	ctx := runCtx
+	mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !dryRun {
+		release, ok, err := tryJobLock(ctx, retentionJobName)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrRetentionInProgress
+		}
+		defer release()
+	}
+
+	// Both steps are recorded as one run
+	return recordRetentionRun(models.RetentionTriggerManual, dryRun, retentionStep(ctx, dryRun), logRetentionStep(dryRun))
+}
+
+// RunRetention deletes the data of all tenants that is older than their retention periods
+// The run and the deleted counts are recorded as RetentionRun, dry runs only count.
+func RunRetention(ctx context.Context, trigger string, dryRun bool) (*RetentionReport, error) {
+	return recordRetentionRun(trigger, dryRun, retentionStep(ctx, dryRun))
+}
+
+// retentionStep deletes (or with dryRun counts) the expired data of all tenants
+func retentionStep(ctx context.Context, dryRun bool) retentionRunStep {
+	return func(report *RetentionReport) error {
+		var tenants []models.Tenant
+		if err := config.DB.Order("id").Find(&tenants).Error; err != nil {
+			return fmt.Errorf("failed to load tenants: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, tenant := range tenants {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("retention cancelled: %w", err)
+			}
+
+			policy, err := GetRetentionPolicy(tenant.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, entity := range retentionEntities(tenant.ID, policy) {
+				if entity.days <= 0 {
+					continue
+				}
+				cutoff := now.AddDate(0, 0, -entity.days)
+
+				query := entity.query(config.DB, cutoff)
+				var count int64
+				if dryRun {
+					err = query.Count(&count).Error
+				} else {
+					result := query.Delete(entity.model)
+					err = result.Error
+					count = result.RowsAffected
+				}
+				if err != nil {
+					return fmt.Errorf("failed to delete %s of tenant %s: %w", entity.name, tenant.Slug, err)
+				}
+
+				tenantID := tenant.ID
+				report.add(models.RetentionRunItem{
+					TenantID:      &tenantID,
+					Entity:        entity.name,
+					RetentionDays: entity.days,
+					Cutoff:        cutoff,
+					Count:         count,
+				})
+			}
+		}
+		return nil
+	}
+}
+
+// RunLogRetention deletes the rotated log files of this instance older than LOG_RETENTION_DAYS
+// The file currently written to is never deleted.
+func RunLogRetention(trigger string, dryRun bool) (*RetentionReport, error) {
+	return recordRetentionRun(trigger, dryRun, logRetentionStep(dryRun))
+}
+
+// logRetentionStep deletes (or with dryRun lists) the expired log files of this instance
+func logRetentionStep(dryRun bool) retentionRunStep {
+	return func(report *RetentionReport) error {
+		days := config.AppConfig.LogRetentionDays
+		cutoff := time.Now().AddDate(0, 0, -days)
+		cutoffDay := cutoff.Format(utils.RotatedLogDateLayout)
+
+		paths, err := filepath.Glob(filepath.Join(utils.LogDir, "*.log"))
+		if err != nil {
+			return fmt.Errorf("failed to list log files: %w", err)
+		}
+
+		var deleted []string
+		for _, path := range paths {
+			match := rotatedLogPattern.FindStringSubmatch(filepath.Base(path))
+			if match == nil || match[1] >= cutoffDay {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(path); err != nil {
+					log.Printf("WARNING: Failed to delete log file %s: %v", path, err)
+					continue
+				}
+			}
+			deleted = append(deleted, filepath.Base(path))
+		}
+
+		item := models.RetentionRunItem{
+			Entity:        models.RetentionEntityLogFiles,
+			RetentionDays: days,
+			Cutoff:        cutoff.UTC(),
+			Count:         int64(len(deleted)),
+		}
+		if len(deleted) > 0 {
+			files := strings.Join(deleted, "\n")
+			item.Files = &files
+		}
+		report.add(item)
+		return nil
+	}
+}
+
+// retentionEntity is a kind of tenant data with a retention period
+type retentionEntity struct {
+	name  string
+	days  int
+	model interface{}
+	query func(db *gorm.DB, cutoff time.Time) *gorm.DB
+}
+
+// retentionEntities returns the expirable data of a tenant with its retention period
+// Deleting timetables also deletes their rooms and change history (ON DELETE CASCADE). Feeds still
+// contain the events of past semesters, the importer skips them instead of creating them again.
+func retentionEntities(tenantID uint, policy *models.RetentionPolicy) []retentionEntity {
+	// Friend requests and custom hours belong to the tenant through their user
+	tenantUsers := func(db *gorm.DB) *gorm.DB {
+		return db.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).Select("id").Where("tenant_id = ?", tenantID)
+	}
+
+	return []retentionEntity{
+		{
+			name:  models.RetentionEntityTimetables,
+			days:  policy.TimetableDays,
+			model: &models.Timetable{},
+			query: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+				return db.Model(&models.Timetable{}).Where("tenant_id = ? AND end_time < ?", tenantID, cutoff)
+			},
+		},
+		{
+			name:  models.RetentionEntityFriendRequests,
+			days:  policy.FriendRequestDays,
+			model: &models.FriendRequest{},
+			query: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+				return db.Model(&models.FriendRequest{}).
+					Where("status = ? AND updated_at < ? AND requester_id IN (?)", "rejected", cutoff, tenantUsers(db))
+			},
+		},
+		{
+			name:  models.RetentionEntityCustomHours,
+			days:  policy.CustomHourDays,
+			model: &models.CustomHour{},
+			query: func(db *gorm.DB, cutoff time.Time) *gorm.DB {
+				return db.Model(&models.CustomHour{}).Where("end_time < ? AND user_id IN (?)", cutoff, tenantUsers(db))
+			},
+		},
+	}
+}
+
+// add appends an item to the report
+func (r *RetentionReport) add(item models.RetentionRunItem) {
+	r.Items = append(r.Items, item)
+	r.TotalCount += item.Count
+}
+
+// retentionRunStep deletes one kind of data and adds what it deleted to the report
+type retentionRunStep func(report *RetentionReport) error
+
+// recordRetentionRun runs retention steps in order and records them with their items as one RetentionRun
+// A failing step ends the run. Items collected before a failure are recorded as well, they were deleted already.
+func recordRetentionRun(trigger string, dryRun bool, steps ...retentionRunStep) (*RetentionReport, error) {
+	report := &RetentionReport{DryRun: dryRun, Items: []models.RetentionRunItem{}}
+	startedAt := time.Now().UTC()
+
+	var stepErr error
+	for _, step := range steps {
+		if stepErr = step(report); stepErr != nil {
+			break
+		}
+	}
+
+	for _, item := range report.Items {
+		if item.Count > 0 {
+			verb := "Deleted"
+			if dryRun {
+				verb = "Would delete"
+			}
+			log.Printf("Retention: %s %d %s older than %s", verb, item.Count, item.Entity, item.Cutoff.Format(time.RFC3339))
+		}
+	}
+
+	if dryRun {
+		return report, stepErr
+	}
+
+	finishedAt := time.Now().UTC()
+	run := models.RetentionRun{
+		Trigger:    trigger,
+		Instance:   config.AppConfig.InstanceID,
+		Status:     models.RetentionRunStatusSuccess,
+		TotalCount: report.TotalCount,
+		StartedAt:  startedAt,
+		FinishedAt: &finishedAt,
+		Items:      report.Items,
+	}
+	if stepErr != nil {
+		message := stepErr.Error()
+		run.Status = models.RetentionRunStatusFailed
+		run.Error = &message
+	}
+
+	if err := config.DB.Create(&run).Error; err != nil {
+		log.Printf("ERROR: Failed to record retention run: %v", err)
+	} else {
+		report.RunID = &run.ID
+		report.Items = run.Items
+	}
+	return report, stepErr
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+)
+
+// DefaultRoomRules returns the NAK room grammar, used for tenants without own rules
+// Rooms are a building letter, the floor digit and two more digits, optionally followed by
+// a single letter for lab variants: "A104", "a 104" -> "A104", "EDV-A102" -> "A102", "A001E"
+func DefaultRoomRules() []models.RoomRule {
+	return []models.RoomRule{
+		{
+			Kind:      models.RoomRulePattern,
+			Pattern:   `^(?:[A-Za-z]+-\s*)?(?P<number>(?P<building>[A-Za-z])\s*(?P<floor>\d)\d{2}(?:[A-Za-z]\b)?)`,
+			Uppercase: true,
+			Position:  10,
+			IsActive:  true,
+		},
+	}
+}
+
+// RoomGrammar is a validated room grammar of a tenant
+type RoomGrammar struct {
+	aliases  map[string]models.RoomRule // Lower-case location text -> alias rule
+	prefixes []string
+	patterns []roomPattern
+}
+
+// roomPattern is a compiled pattern rule
+type roomPattern struct {
+	re        *regexp.Regexp
+	uppercase bool
+}
+
+// RoomMatch is a room recognized by a RoomGrammar
+type RoomMatch struct {
+	RoomNumber string
+	Building   string
+	Floor      string
+}
+
+// CompileRoomRules validates rules and builds the grammar, inactive rules are skipped
+func CompileRoomRules(rules []models.RoomRule) (*RoomGrammar, error) {
+	sorted := make([]models.RoomRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	grammar := &RoomGrammar{aliases: make(map[string]models.RoomRule)}
+	for i, rule := range sorted {
+		if strings.TrimSpace(rule.Pattern) == "" {
+			return nil, fmt.Errorf("rule %d: pattern is required", i+1)
+		}
+
+		switch rule.Kind {
+		case models.RoomRuleAlias:
+			if strings.TrimSpace(rule.RoomNumber) == "" {
+				return nil, fmt.Errorf("rule %d: alias %q needs a room_number", i+1, rule.Pattern)
+			}
+			if rule.IsActive {
+				grammar.aliases[strings.ToLower(strings.TrimSpace(rule.Pattern))] = rule
+			}
+		case models.RoomRulePrefix:
+			if rule.IsActive {
+				grammar.prefixes = append(grammar.prefixes, rule.Pattern)
+			}
+		case models.RoomRulePattern:
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid regular expression: %w", i+1, err)
+			}
+			if re.SubexpIndex("number") == -1 {
+				return nil, fmt.Errorf("rule %d: pattern needs a named group (?P<number>...)", i+1)
+			}
+			if rule.IsActive {
+				grammar.patterns = append(grammar.patterns, roomPattern{re: re, uppercase: rule.Uppercase})
+			}
+		default:
+			return nil, fmt.Errorf("rule %d: unknown kind %q", i+1, rule.Kind)
+		}
+	}
+
+	return grammar, nil
+}
+
+// LoadRoomGrammar loads the active room rules of a tenant, or the default grammar if it has none stored
+// A tenant that deactivated all its rules resolves no rooms (same as GetRoomRules isDefault)
+func LoadRoomGrammar(db *gorm.DB, tenantID uint) (*RoomGrammar, error) {
+	var rules []models.RoomRule
+	if err := db.Where("tenant_id = ?", tenantID).
+		Order("position ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load room rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		rules = DefaultRoomRules()
+	}
+
+	return CompileRoomRules(rules)
+}
+
+// GetRoomRules returns the stored room rules of a tenant (active and inactive)
+// isDefault is true if the tenant has no rules and the default grammar applies
+func GetRoomRules(tenantID uint) (rules []models.RoomRule, isDefault bool, err error) {
+	if err := config.DB.Where("tenant_id = ?", tenantID).Order("position ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to load room rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		return DefaultRoomRules(), true, nil
+	}
+	return rules, false, nil
+}
+
+// SaveRoomRules replaces the room grammar of a tenant
+// An empty list deletes the tenant's rules, so the default grammar applies again
+func SaveRoomRules(tenantID uint, rules []models.RoomRule) error {
+	if _, err := CompileRoomRules(rules); err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&models.RoomRule{}).Error; err != nil {
+			return fmt.Errorf("failed to delete room rules: %w", err)
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+
+		for i := range rules {
+			rules[i].ID = 0
+			rules[i].TenantID = tenantID
+		}
+		if err := tx.Create(&rules).Error; err != nil {
+			return fmt.Errorf("failed to save room rules: %w", err)
+		}
+		return nil
+	})
+}
+
+// Parse recognizes the room of a single location, e.g. "EDV-A102" or "Audimax"
+// Returns false if the location is no room (it is then kept as free-text location)
+func (g *RoomGrammar) Parse(location string) (RoomMatch, bool) {
+	location = strings.TrimSpace(location)
+
+	// Stop at backslash (prevents "C007\nAnmerkung" -> "C007NANMERKUNG")
+	if idx := strings.Index(location, "\\"); idx != -1 {
+		location = strings.TrimSpace(location[:idx])
+	}
+	if location == "" {
+		return RoomMatch{}, false
+	}
+
+	if match, ok := g.alias(location); ok {
+		return match, true
+	}
+
+	for _, prefix := range g.prefixes {
+		if len(location) >= len(prefix) && strings.EqualFold(location[:len(prefix)], prefix) {
+			location = strings.TrimSpace(location[len(prefix):])
+			break
+		}
+	}
+
+	// Aliases may also be written with a prefix, e.g. "EDV-Labor"
+	if match, ok := g.alias(location); ok {
+		return match, true
+	}
+
+	for _, pattern := range g.patterns {
+		groups := pattern.re.FindStringSubmatch(location)
+		if groups == nil {
+			continue
+		}
+
+		match := RoomMatch{
+			RoomNumber: strings.Join(strings.Fields(groups[pattern.re.SubexpIndex("number")]), ""),
+			Building:   subexpValue(pattern.re, groups, "building"),
+			Floor:      subexpValue(pattern.re, groups, "floor"),
+		}
+		if match.RoomNumber == "" {
+			continue
+		}
+		if pattern.uppercase {
+			match.RoomNumber = strings.ToUpper(match.RoomNumber)
+			match.Building = strings.ToUpper(match.Building)
+		}
+		return match, true
+	}
+
+	return RoomMatch{}, false
+}
+
+// alias returns the room of an alias rule matching location (case-insensitive)
+func (g *RoomGrammar) alias(location string) (RoomMatch, bool) {
+	rule, ok := g.aliases[strings.ToLower(location)]
+	if !ok {
+		return RoomMatch{}, false
+	}
+	return RoomMatch{
+		RoomNumber: strings.TrimSpace(rule.RoomNumber),
+		Building:   strings.TrimSpace(rule.Building),
+		Floor:      strings.TrimSpace(rule.Floor),
+	}, true
+}
+
+// subexpValue returns the trimmed value of a named group, or "" if the pattern has no such group
+func subexpValue(re *regexp.Regexp, groups []string, name string) string {
+	idx := re.SubexpIndex(name)
+	if idx == -1 {
+		return ""
+	}
+	return strings.TrimSpace(groups[idx])
+}
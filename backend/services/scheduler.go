@@ -1,29 +1,55 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
 	"github.com/nora-nak/backend/utils"
 	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
 )
 
 var (
 	scheduler *cron.Cron
 	mu        sync.Mutex
 	isRunning bool
+
+	// jobEntries maps job names to their cron entries, jobConfigs holds the settings they were scheduled with
+	jobEntries map[string]cron.EntryID
+	jobConfigs map[string]jobSettings
+
+	// runningJobs holds the names of jobs with a run in progress
+	runningJobs sync.Map
+
+	// runCtx is cancelled by StopScheduler to abort in-flight imports
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+
+	// startupRun tracks the import started outside of cron, so StopScheduler can wait for it
+	startupRun sync.WaitGroup
 )
 
 // SchedulerStatus represents the scheduler status
+// NextRun and JobName refer to the timetable import job
 type SchedulerStatus struct {
-	Status  string     `json:"status"`
-	Running bool       `json:"running"`
-	NextRun *time.Time `json:"next_run,omitempty"`
-	JobName string     `json:"job_name,omitempty"`
+	Status            string              `json:"status"`
+	Running           bool                `json:"running"`
+	NextRun           *time.Time          `json:"next_run,omitempty"`
+	JobName           string              `json:"job_name,omitempty"`
+	Instance          string              `json:"instance,omitempty"` // Instance that answered the request (admins only)
+	Jobs              []JobStatus         `json:"jobs"`
+	LastSuccessfulRun *models.ImportRun   `json:"last_successful_run,omitempty"`
+	FeedBreakers      []FeedBreakerStatus `json:"feed_breakers"` // Failing feed URLs of the tenant
 }
 
-// StartScheduler starts the cron scheduler for timetable updates
+// StartScheduler starts the cron scheduler with all enabled jobs
 func StartScheduler(runImmediately bool) error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -37,41 +63,34 @@ func StartScheduler(runImmediately bool) error {
 
 	// Create new cron scheduler
 	scheduler = cron.New(cron.WithLocation(time.UTC))
-
-	// Add job every 15 minutes (at :00, :15, :30, :45)
-	_, err := scheduler.AddFunc("*/15 * * * *", func() {
-		log.Println("=============================================================")
-		log.Println("TIMETABLE UPDATE STARTED (Every 15 minutes)")
-		log.Printf("Time (UTC): %s", time.Now().UTC().Format(time.RFC3339))
-		log.Println("=============================================================")
-
-		if err := FetchAndImportTimetables(); err != nil {
-			log.Printf("ERROR during timetable update: %v", err)
-			log.Println("Scheduler will retry in 15 minutes")
-		} else {
-			log.Println("=============================================================")
-			log.Println("TIMETABLE UPDATE COMPLETED")
-			log.Println("=============================================================")
+	runCtx, cancelRun = context.WithCancel(context.Background())
+	ctx := runCtx
+
+	jobEntries = make(map[string]cron.EntryID)
+	jobConfigs = loadJobSettings()
+	for _, job := range schedulerJobs() {
+		if err := scheduleJob(ctx, job, jobConfigs[job.Name]); err != nil {
+			cancelRun()
+			scheduler = nil
+			return err
 		}
-	})
-
-	if err != nil {
-		return err
 	}
 
 	// Start scheduler
 	scheduler.Start()
 	isRunning = true
 
-	log.Println("Scheduler started - Running every 15 minutes")
+	log.Printf("Scheduler started - %d job(s) scheduled", len(jobEntries))
 
-	// Optionally run immediately
-	if runImmediately {
+	// Optionally run immediately, skipped if another instance is already importing
+	if runImmediately && jobConfigs[importJobName].Enabled {
 		log.Println("Running first import immediately...")
+		startupRun.Add(1)
 		go func() {
-			if err := FetchAndImportTimetables(); err != nil {
-				log.Printf("ERROR during immediate import: %v", err)
-			}
+			defer startupRun.Done()
+			runJob(ctx, importJobName, true, func() error {
+				return FetchAndImportTimetables(ctx, models.ImportTriggerStartup)
+			})
 		}()
 	}
 
@@ -89,106 +108,385 @@ func StopScheduler() {
 	}
 
 	log.Println("Stopping scheduler service...")
+	cancelRun() // Abort in-flight downloads, then wait for the running jobs to return
 	ctx := scheduler.Stop()
 	<-ctx.Done()
+	startupRun.Wait()
 	isRunning = false
 	scheduler = nil
+	jobEntries = nil
+	jobConfigs = nil
 	log.Println("Scheduler stopped")
 }
 
-// GetSchedulerStatus returns the current scheduler status
+// GetSchedulerStatus returns the current scheduler status with the next and previous run of every job
 func GetSchedulerStatus() SchedulerStatus {
 	mu.Lock()
 	defer mu.Unlock()
 
+	jobs := getJobStatuses()
+
 	if !isRunning || scheduler == nil {
 		return SchedulerStatus{
-			Status:  "stopped",
-			Running: false,
-			NextRun: nil,
+			Status:   "stopped",
+			Running:  false,
+			NextRun:  nil,
+			Instance: config.AppConfig.InstanceID,
+			Jobs:     jobs,
+		}
+	}
+
+	status := SchedulerStatus{
+		Status:   "running",
+		Running:  true,
+		Instance: config.AppConfig.InstanceID,
+		Jobs:     jobs,
+	}
+	for _, job := range jobs {
+		if job.Name == importJobName {
+			status.NextRun = job.NextRun
+			status.JobName = job.Description
+		}
+	}
+	return status
+}
+
+// FetchAndImportTimetables fetches ICS files and imports them to database
+// Runs the fetch -> parse -> import pipeline once per active tenant, stops early when ctx is cancelled
+// trigger is recorded in the import runs (cron, startup or manual)
+func FetchAndImportTimetables(ctx context.Context, trigger string) error {
+	var tenants []models.Tenant
+	if err := config.DB.Where("is_active = ?", true).Find(&tenants).Error; err != nil {
+		return fmt.Errorf("failed to load tenants: %w", err)
+	}
+
+	if len(tenants) == 0 {
+		log.Println("WARNING: No active tenants found, nothing to import")
+		return nil
+	}
+
+	var failedTenants []string
+	for i := range tenants {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("import cancelled: %w", err)
+		}
+
+		tenant := &tenants[i]
+		log.Printf("=== Tenant %s (%s) ===", tenant.Slug, tenant.Name)
+
+		if _, err := FetchAndImportTenantTimetables(ctx, tenant, TenantImportOptions{Trigger: trigger}); err != nil {
+			log.Printf("ERROR importing timetables for tenant %s: %v", tenant.Slug, err)
+			failedTenants = append(failedTenants, tenant.Slug)
+		}
+	}
+
+	if len(failedTenants) > 0 {
+		return fmt.Errorf("import failed for tenant(s): %s", strings.Join(failedTenants, ", "))
+	}
+
+	return nil
+}
+
+// TenantImportOptions controls a single FetchAndImportTenantTimetables call
+type TenantImportOptions struct {
+	Trigger  string // cron, startup or manual (recorded in the import run)
+	Zenturie string // Only import this zenturie, empty for all zenturien of the tenant
+	DryRun   bool   // Compute the changes without writing anything (no import run, no fetch states)
+}
+
+// TenantImportResult is the outcome of a single tenant import
+type TenantImportResult struct {
+	RunID *uint // nil for dry runs or if the run could not be stored
+	Stats *ImportStatistics
+	Files []models.ImportRunFileResult
+}
+
+// ErrImportInProgress is returned for manual imports while another import is running
+var ErrImportInProgress = errors.New("an import is already running")
+
+// ErrNoActiveFeedSource is returned for manual imports of tenants without an active feed source
+var ErrNoActiveFeedSource = errors.New("tenant has no active feed source")
+
+// ErrZenturieNotFound is returned for manual imports of a zenturie the tenant does not have
+var ErrZenturieNotFound = errors.New("zenturie not found")
+
+// importMu serializes imports, so manual runs never overlap with the cron job
+var importMu sync.Mutex
+
+// FetchAndImportTenantTimetables fetches and imports the ICS files of a single tenant
+// Scheduled runs wait for a running import, manual runs fail with ErrImportInProgress
+// (also while another instance imports)
+func FetchAndImportTenantTimetables(ctx context.Context, tenant *models.Tenant, opts TenantImportOptions) (*TenantImportResult, error) {
+	manual := opts.Trigger == models.ImportTriggerManual
+
+	source, err := GetFeedSource(tenant.ID)
+	if err != nil {
+		if errors.Is(err, ErrNoFeedSource) {
+			log.Printf("Tenant %s has no feed source configured, skipping", tenant.Slug)
+			if manual {
+				return nil, ErrNoActiveFeedSource
+			}
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !source.IsActive {
+		log.Printf("Feed source of tenant %s is disabled, skipping", tenant.Slug)
+		if manual {
+			return nil, ErrNoActiveFeedSource
+		}
+		return nil, nil
+	}
+
+	adapter, err := GetTimetableSource(source.Adapter)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Zenturie != "" {
+		var zenturie models.Zenturie
+		err := config.DB.Where("tenant_id = ? AND LOWER(name) = LOWER(?)", tenant.ID, opts.Zenturie).First(&zenturie).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrZenturieNotFound
 		}
+		if err != nil {
+			return nil, err
+		}
+		opts.Zenturie = zenturie.Name
 	}
 
-	// Get next run time (approximate based on 15-minute schedule)
-	now := time.Now()
-	minute := now.Minute()
+	if manual {
+		if !importMu.TryLock() {
+			return nil, ErrImportInProgress
+		}
+		defer importMu.Unlock()
 
-	// Calculate next 15-minute mark (00, 15, 30, 45)
-	var nextRun time.Time
-	if minute < 15 {
-		nextRun = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 15, 0, 0, now.Location())
-	} else if minute < 30 {
-		nextRun = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 30, 0, 0, now.Location())
-	} else if minute < 45 {
-		nextRun = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 45, 0, 0, now.Location())
+		// Scheduled runs already hold the cluster-wide lock of the import job
+		release, err := tryImportLock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
 	} else {
-		nextRun = time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+		importMu.Lock()
+		defer importMu.Unlock()
 	}
 
-	return SchedulerStatus{
-		Status:  "running",
-		Running: true,
-		NextRun: &nextRun,
-		JobName: "Timetable Update (Every 15 minutes)",
+	var run *models.ImportRun
+	if !opts.DryRun {
+		run = startImportRun(tenant.ID, opts.Trigger)
+	}
+	result := &TenantImportResult{}
+	if run != nil {
+		result.RunID = &run.ID
 	}
+
+	result.Stats, result.Files, err = runTenantImport(ctx, tenant, source, adapter, opts, result.RunID)
+	finishImportRun(run, result.Stats, result.Files, err)
+	return result, err
 }
 
-// FetchAndImportTimetables fetches ICS files and imports them to database
-func FetchAndImportTimetables() error {
-	log.Println("[1/3] Fetching ICS files...")
+// RunManualImport runs an import of one tenant started by an admin
+// The import is aborted by StopScheduler like scheduled runs
+func RunManualImport(tenant *models.Tenant, zenturie string, dryRun bool) (*TenantImportResult, error) {
+	mu.Lock()
+	ctx := runCtx
+	mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return FetchAndImportTenantTimetables(ctx, tenant, TenantImportOptions{
+		Trigger:  models.ImportTriggerManual,
+		Zenturie: zenturie,
+		DryRun:   dryRun,
+	})
+}
+
+// runTenantImport runs fetch -> parse -> import for a tenant, recording changes under runID
+// Returns the statistics (nil if the database step was not reached) and the per-file results
+func runTenantImport(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, adapter TimetableSource, opts TenantImportOptions, runID *uint) (*ImportStatistics, []models.ImportRunFileResult, error) {
+	// Dry runs leave no trace in the import statistics log
+	logStatistics := func(stats ImportStatistics) {
+		if opts.DryRun {
+			return
+		}
+		if err := utils.LogICSImportStatistics(
+			tenant.Slug,
+			stats.FilesDownloaded,
+			stats.FilesSkippedUnchanged,
+			stats.EventsCreated,
+			stats.EventsUpdated,
+			stats.EventsUnchanged,
+			stats.EventsCancelled,
+			stats.Errors,
+		); err != nil {
+			log.Printf("WARNING: Failed to write to log file: %v", err)
+			// Don't fail the import if logging fails
+		} else {
+			log.Println("Successfully logged import statistics to ics_data_imports.log")
+		}
+	}
+
+	log.Printf("[1/3] Fetching timetable files (adapter: %s)...", source.Adapter)
 
 	// Step 1: Fetch ICS files
-	icsData, err := FetchICSFiles()
+	icsData, err := adapter.Fetch(ctx, tenant, source, FetchOptions{Zenturie: opts.Zenturie})
 	if err != nil {
-		// Log error
-		if logErr := utils.LogICSImportStatistics(0, 0, 0, 0, 1); logErr != nil {
-			log.Printf("WARNING: Failed to write to log file: %v", logErr)
+		logStatistics(ImportStatistics{Errors: 1})
+		return nil, nil, err
+	}
+
+	if opts.Zenturie != "" {
+		icsData = filterZenturie(icsData, opts.Zenturie)
+		if len(icsData) == 0 {
+			return nil, nil, fmt.Errorf("no timetable files found for zenturie %s", opts.Zenturie)
+		}
+	}
+
+	// Archive the fetched content before parsing, so files the parser chokes on can be inspected
+	if !opts.DryRun {
+		if err := SaveFeedSnapshots(tenant.ID, runID, icsData); err != nil {
+			log.Printf("WARNING: Failed to archive feed snapshots: %v", err)
 		}
-		return err
 	}
 
-	filesDownloaded := len(icsData)
-	log.Printf("[1/3] Fetched %d ICS files", filesDownloaded)
+	filesSkipped := 0
+	filesFailed := 0
+	for _, data := range icsData {
+		switch {
+		case data.Error != "":
+			filesFailed++
+		case data.Unchanged:
+			filesSkipped++
+		}
+	}
+	filesDownloaded := len(icsData) - filesSkipped - filesFailed
+	log.Printf("[1/3] Fetched %d ICS files (%d unchanged, skipped)", filesDownloaded, filesSkipped)
 	log.Println("[2/3] Parsing ICS files...")
 
 	// Step 2: Parse ICS to events
-	events, err := ParseICSFiles(icsData)
+	events, err := ParseICSFiles(icsData, TenantLocation(tenant))
 	if err != nil {
-		// Log error
-		if logErr := utils.LogICSImportStatistics(filesDownloaded, 0, 0, 0, 1); logErr != nil {
-			log.Printf("WARNING: Failed to write to log file: %v", logErr)
-		}
-		return err
+		logStatistics(ImportStatistics{FilesDownloaded: filesDownloaded, FilesSkippedUnchanged: filesSkipped, Errors: 1})
+		return nil, buildFileResults(icsData, nil), err
 	}
 
 	log.Printf("[2/3] Parsed %d events", len(events))
-	log.Println("[3/3] Importing events to database...")
+	if opts.DryRun {
+		log.Println("[3/3] Computing changes (dry run, nothing is written)...")
+	} else {
+		log.Println("[3/3] Importing events to database...")
+	}
 
 	// Step 3: Import to database
-	stats, err := ImportEventsToDatabase(events)
+	guards := &ImportGuards{MaxDropPercent: source.GuardMaxDropPercent, MaxMovedEvents: source.GuardMaxMovedEvents}
+	stats, err := ImportEventsToDatabase(ImportOptions{
+		TenantID:    tenant.ID,
+		RunID:       runID,
+		DryRun:      opts.DryRun,
+		Guards:      guards,
+		SourceFiles: parsedSourceFiles(icsData),
+	}, events)
 	if err != nil {
-		// Log error
-		if logErr := utils.LogICSImportStatistics(filesDownloaded, 0, 0, 0, 1); logErr != nil {
-			log.Printf("WARNING: Failed to write to log file: %v", logErr)
-		}
-		return err
+		logStatistics(ImportStatistics{FilesDownloaded: filesDownloaded, FilesSkippedUnchanged: filesSkipped, Errors: 1})
+		return nil, buildFileResults(icsData, events), err
 	}
 
 	log.Println("[3/3] Events imported successfully")
 
+	stats.FilesDownloaded = filesDownloaded
+	stats.FilesSkippedUnchanged = filesSkipped
+	stats.FilesFailed = filesFailed
+	for _, data := range icsData {
+		if !data.Unchanged {
+			stats.Errors += data.InvalidEvents
+		}
+	}
+
+	// Remember ETag/Last-Modified/hash only for imported files, rolled back and
+	// quarantined files have to be fetched and imported again
+	if !opts.DryRun {
+		if err := SaveFeedFetchStates(tenant.ID, importedFiles(icsData, stats)); err != nil {
+			log.Printf("WARNING: Failed to save feed fetch states: %v", err)
+		}
+	}
+
 	// Step 4: Log import statistics to file
-	if err := utils.LogICSImportStatistics(
-		filesDownloaded,
-		stats.EventsCreated,
-		stats.EventsUpdated,
-		stats.EventsUnchanged,
-		stats.Errors,
-	); err != nil {
-		log.Printf("WARNING: Failed to write to log file: %v", err)
-		// Don't fail the import if logging fails
-	} else {
-		log.Println("Successfully logged import statistics to ics_data_imports.log")
+	logStatistics(*stats)
+
+	// Quarantined files wait for an admin, notify the team once per new file
+	if quarantined := quarantineSummary(stats.Quarantined); len(quarantined) > 0 && !opts.DryRun {
+		log.Printf("%d file(s) quarantined, sending notification", len(quarantined))
+		if err := utils.NewEmailService().SendICSImportNotification(
+			stats.FilesDownloaded,
+			stats.EventsCreated,
+			stats.EventsUpdated,
+			stats.Errors,
+			quarantined,
+		); err != nil {
+			log.Printf("WARNING: Failed to send quarantine notification: %v", err)
+		}
 	}
 
-	return nil
+	files := buildFileResults(icsData, events)
+	markFailedFiles(files, stats.Zenturien)
+	markQuarantinedFiles(files, stats.Quarantined)
+	return stats, files, nil
+}
+
+// parsedSourceFiles returns the files parsed in this run per zenturie, including files without events
+func parsedSourceFiles(icsData []ICSData) map[string][]string {
+	files := make(map[string][]string)
+	for _, data := range icsData {
+		if data.Unchanged || data.Error != "" || data.SourceFile == "" {
+			continue
+		}
+		files[data.Zenturie] = append(files[data.Zenturie], data.SourceFile)
+	}
+	return files
+}
+
+// importedFiles returns the fetched files whose events were all written
+// Files sharing a URL (JSON documents) are dropped together if any part was not imported.
+func importedFiles(icsData []ICSData, stats *ImportStatistics) []ICSData {
+	notImported := make(map[string]bool)
+	for _, zs := range stats.Zenturien {
+		for _, sourceFile := range zs.FailedFiles {
+			notImported[zs.Zenturie+"/"+sourceFile] = true
+		}
+	}
+	for _, q := range stats.Quarantined {
+		notImported[q.Zenturie+"/"+q.SourceFile] = true
+	}
+	if len(notImported) == 0 {
+		return icsData
+	}
+
+	failedURLs := make(map[string]bool)
+	for _, data := range icsData {
+		if notImported[data.Zenturie+"/"+data.SourceFile] {
+			failedURLs[data.URL] = true
+		}
+	}
+
+	imported := make([]ICSData, 0, len(icsData))
+	for _, data := range icsData {
+		if !failedURLs[data.URL] {
+			imported = append(imported, data)
+		}
+	}
+	return imported
+}
+
+// filterZenturie returns the files of a single zenturie
+func filterZenturie(icsData []ICSData, zenturie string) []ICSData {
+	var filtered []ICSData
+	for _, data := range icsData {
+		if strings.EqualFold(data.Zenturie, zenturie) {
+			filtered = append(filtered, data)
+		}
+	}
+	return filtered
 }
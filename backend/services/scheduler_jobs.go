@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm/clause"
+)
+
+// Errors returned by UpdateSchedulerJob
+var (
+	ErrJobNotFound     = errors.New("scheduler job not found")
+	ErrInvalidSchedule = errors.New("invalid cron expression")
+)
+
+// importJobName is the job of the timetable import, manual imports share its lock
+const importJobName = "ics_import"
+
+// Job is a named background job of the scheduler
+type Job struct {
+	Name        string // Also used in JOB_<NAME>_SCHEDULE and JOB_<NAME>_ENABLED (upper-cased)
+	Description string
+	Schedule    string // Default cron expression (standard 5-field syntax, UTC)
+	Enabled     bool   // Default enable flag
+	PerInstance bool   // Runs on every instance (e.g. local files) instead of once per cluster
+	Run         func(ctx context.Context) error
+}
+
+// Job setting sources, in increasing precedence
+const (
+	JobSourceDefault  = "default"
+	JobSourceEnv      = "env"
+	JobSourceDatabase = "database"
+)
+
+// JobStatus is the state of one scheduler job
+type JobStatus struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	Enabled     bool       `json:"enabled"`
+	Source      string     `json:"source"` // Where schedule and enable flag come from: default, env, database
+	Running     bool       `json:"running"`
+	LockHolder  string     `json:"lock_holder,omitempty"` // Instance currently running the job, on any replica
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	PrevRun     *time.Time `json:"prev_run,omitempty"` // Last start since the scheduler was started
+}
+
+// jobSettings is the effective schedule of a job
+type jobSettings struct {
+	Schedule string
+	Enabled  bool
+	Source   string
+}
+
+// schedulerJobs returns the jobs of the scheduler
+func schedulerJobs() []Job {
+	return []Job{
+		{
+			Name:        importJobName,
+			Description: "Timetable import of all active tenants",
+			Schedule:    "*/15 * * * *",
+			Enabled:     true,
+			Run:         runImportJob,
+		},
+		{
+			Name:        retentionJobName,
+			Description: "Deletes tenant data past its retention period",
+			Schedule:    "30 3 * * *",
+			Enabled:     true,
+			Run:         runRetentionJob,
+		},
+		{
+			Name:        logRetentionJobName,
+			Description: "Deletes rotated log files of the instance past LOG_RETENTION_DAYS",
+			Schedule:    "45 3 * * *",
+			Enabled:     true,
+			PerInstance: true,
+			Run:         runLogRetentionJob,
+		},
+	}
+}
+
+// findJob returns the registered job with the given name
+func findJob(name string) (Job, bool) {
+	for _, job := range schedulerJobs() {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// runImportJob is the scheduled timetable import
+func runImportJob(ctx context.Context) error {
+	log.Println("=============================================================")
+	log.Println("TIMETABLE UPDATE STARTED")
+	log.Printf("Time (UTC): %s", time.Now().UTC().Format(time.RFC3339))
+	log.Println("=============================================================")
+
+	if err := FetchAndImportTimetables(ctx, models.ImportTriggerCron); err != nil {
+		return err
+	}
+
+	log.Println("=============================================================")
+	log.Println("TIMETABLE UPDATE COMPLETED")
+	log.Println("=============================================================")
+	return nil
+}
+
+// loadJobSettings resolves the effective settings of all jobs: database over environment over default
+// Invalid expressions from the environment are ignored with a warning
+func loadJobSettings() map[string]jobSettings {
+	overrides := make(map[string]models.SchedulerJob)
+	var rows []models.SchedulerJob
+	if err := config.DB.Find(&rows).Error; err != nil {
+		log.Printf("WARNING: Failed to load scheduler job settings: %v", err)
+	}
+	for _, row := range rows {
+		overrides[row.Name] = row
+	}
+
+	settings := make(map[string]jobSettings)
+	for _, job := range schedulerJobs() {
+		s := jobSettings{Schedule: job.Schedule, Enabled: job.Enabled, Source: JobSourceDefault}
+
+		schedule, enabled := config.GetJobEnv(job.Name)
+		if schedule != "" {
+			if _, err := cron.ParseStandard(schedule); err != nil {
+				log.Printf("WARNING: Ignoring invalid schedule %q of job %s: %v", schedule, job.Name, err)
+			} else {
+				s.Schedule = schedule
+				s.Source = JobSourceEnv
+			}
+		}
+		if enabled != nil {
+			s.Enabled = *enabled
+			s.Source = JobSourceEnv
+		}
+
+		if row, ok := overrides[job.Name]; ok {
+			if row.Schedule != nil {
+				s.Schedule = *row.Schedule
+				s.Source = JobSourceDatabase
+			}
+			if row.Enabled != nil {
+				s.Enabled = *row.Enabled
+				s.Source = JobSourceDatabase
+			}
+		}
+
+		settings[job.Name] = s
+	}
+	return settings
+}
+
+// scheduleJob adds a job to the running scheduler, caller must hold mu
+// Runs of a job never overlap, a run still in progress skips the next one
+func scheduleJob(ctx context.Context, job Job, settings jobSettings) error {
+	if !settings.Enabled {
+		log.Printf("Job %s is disabled", job.Name)
+		return nil
+	}
+
+	run := cron.FuncJob(func() {
+		runJob(ctx, job.Name, !job.PerInstance, func() error { return job.Run(ctx) })
+	})
+
+	id, err := scheduler.AddJob(settings.Schedule, cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(run))
+	if err != nil {
+		return fmt.Errorf("failed to schedule job %s: %w", job.Name, err)
+	}
+
+	jobEntries[job.Name] = id
+	log.Printf("Job %s scheduled: %s", job.Name, settings.Schedule)
+	return nil
+}
+
+// runJob runs a job, with clusterLock only if no other instance of the cluster is already running it
+func runJob(ctx context.Context, name string, clusterLock bool, fn func() error) {
+	runningJobs.Store(name, true)
+	defer runningJobs.Delete(name)
+
+	if !clusterLock {
+		if err := fn(); err != nil {
+			log.Printf("ERROR in job %s: %v", name, err)
+		}
+		return
+	}
+
+	ran, err := withJobLock(ctx, name, fn)
+	if err != nil {
+		log.Printf("ERROR in job %s: %v", name, err)
+		return
+	}
+	if !ran {
+		log.Printf("Job %s skipped, it is running on another instance", name)
+	}
+}
+
+// getJobStatuses returns the state of all jobs, caller must hold mu
+func getJobStatuses() []JobStatus {
+	settings := jobConfigs
+	if settings == nil {
+		settings = loadJobSettings()
+	}
+
+	holders := getJobLockHolders()
+
+	var statuses []JobStatus
+	for _, job := range schedulerJobs() {
+		s := settings[job.Name]
+		status := JobStatus{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    s.Schedule,
+			Enabled:     s.Enabled,
+			Source:      s.Source,
+			LockHolder:  holders[job.Name],
+		}
+		if _, running := runningJobs.Load(job.Name); running {
+			status.Running = true
+		}
+
+		if id, ok := jobEntries[job.Name]; ok && scheduler != nil {
+			entry := scheduler.Entry(id)
+			if !entry.Next.IsZero() {
+				next := entry.Next
+				status.NextRun = &next
+			}
+			if !entry.Prev.IsZero() {
+				prev := entry.Prev
+				status.PrevRun = &prev
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// GetSchedulerJobs returns the state of all scheduler jobs
+func GetSchedulerJobs() []JobStatus {
+	mu.Lock()
+	defer mu.Unlock()
+	return getJobStatuses()
+}
+
+// UpdateSchedulerJob stores the schedule and/or enable flag of a job and applies it to the running scheduler
+// An empty schedule removes the stored schedule, so the environment or default applies again.
+func UpdateSchedulerJob(name string, schedule *string, enabled *bool) (*JobStatus, error) {
+	job, ok := findJob(name)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	row := models.SchedulerJob{Name: name}
+	config.DB.Where("name = ?", name).First(&row)
+
+	if schedule != nil {
+		if value := strings.TrimSpace(*schedule); value == "" {
+			row.Schedule = nil
+		} else {
+			if _, err := cron.ParseStandard(value); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+			}
+			row.Schedule = &value
+		}
+	}
+	if enabled != nil {
+		row.Enabled = enabled
+	}
+
+	err := config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"schedule", "enabled", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save scheduler job: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if isRunning && scheduler != nil {
+		jobConfigs = loadJobSettings()
+		if id, ok := jobEntries[name]; ok {
+			scheduler.Remove(id)
+			delete(jobEntries, name)
+		}
+		if err := scheduleJob(runCtx, job, jobConfigs[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, status := range getJobStatuses() {
+		if status.Name == name {
+			return &status, nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
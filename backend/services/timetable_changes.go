@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+)
+
+// changeRecorder collects the timetable changes of an import and writes them to the change history
+type changeRecorder struct {
+	db       *gorm.DB
+	tenantID uint
+	runID    *uint
+	pending  []models.TimetableChange
+
+	// Caches for resolving IDs to readable values
+	roomNumbers map[uint]string
+	courseNames map[uint]string
+}
+
+// newChangeRecorder creates a recorder for one import run of a tenant
+func newChangeRecorder(db *gorm.DB, tenantID uint, runID *uint) *changeRecorder {
+	return &changeRecorder{
+		db:          db,
+		tenantID:    tenantID,
+		runID:       runID,
+		roomNumbers: make(map[uint]string),
+		courseNames: make(map[uint]string),
+	}
+}
+
+// created records a newly imported event
+func (r *changeRecorder) created(tt *models.Timetable) {
+	r.pending = append(r.pending, models.TimetableChange{
+		TenantID:    r.tenantID,
+		ZenturienID: tt.ZenturienID,
+		ImportRunID: r.runID,
+		TimetableID: tt.ID,
+		UID:         tt.UID,
+		ChangeType:  models.TimetableChangeCreated,
+	})
+}
+
+// updated records one row per changed field of an existing event
+func (r *changeRecorder) updated(tt *models.Timetable, changes []fieldChange) {
+	for _, change := range changes {
+		field := change.Field
+		r.pending = append(r.pending, models.TimetableChange{
+			TenantID:    r.tenantID,
+			ZenturienID: tt.ZenturienID,
+			ImportRunID: r.runID,
+			TimetableID: tt.ID,
+			UID:         tt.UID,
+			ChangeType:  models.TimetableChangeUpdated,
+			Field:       &field,
+			OldValue:    r.resolve(field, change.OldValue),
+			NewValue:    r.resolve(field, change.NewValue),
+		})
+	}
+}
+
+// cancelled records events that vanished from their feed
+func (r *changeRecorder) cancelled(timetables []models.Timetable) {
+	field := "status"
+	oldValue := models.TimetableStatusActive
+	newValue := models.TimetableStatusCancelled
+
+	for _, tt := range timetables {
+		r.pending = append(r.pending, models.TimetableChange{
+			TenantID:    r.tenantID,
+			ZenturienID: tt.ZenturienID,
+			ImportRunID: r.runID,
+			TimetableID: tt.ID,
+			UID:         tt.UID,
+			ChangeType:  models.TimetableChangeCancelled,
+			Field:       &field,
+			OldValue:    &oldValue,
+			NewValue:    &newValue,
+		})
+	}
+}
+
+// flush writes all pending changes to the database
+func (r *changeRecorder) flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	if err := r.db.CreateInBatches(r.pending, 500).Error; err != nil {
+		return fmt.Errorf("failed to save %d timetable changes: %w", len(r.pending), err)
+	}
+
+	r.pending = r.pending[:0]
+	return nil
+}
+
+// resolve converts room and course IDs into room numbers and course names
+// Room values are lists of IDs ("12, 7"), resolved to "A103, A102"
+func (r *changeRecorder) resolve(field string, value *string) *string {
+	if value == nil {
+		return value
+	}
+
+	switch field {
+	case "room":
+		ids := strings.Split(*value, ", ")
+		numbers := make([]string, 0, len(ids))
+		for _, id := range ids {
+			number, ok := r.roomNumber(id)
+			if !ok {
+				return value
+			}
+			numbers = append(numbers, number)
+		}
+		resolved := strings.Join(numbers, ", ")
+		return &resolved
+	case "course":
+		id64, err := strconv.ParseUint(*value, 10, 64)
+		if err != nil {
+			return value
+		}
+		id := uint(id64)
+
+		if name, ok := r.courseNames[id]; ok {
+			return &name
+		}
+		var course models.Course
+		if err := r.db.Select("name").First(&course, id).Error; err != nil {
+			return value
+		}
+		r.courseNames[id] = course.Name
+		return &course.Name
+	}
+	return value
+}
+
+// roomNumber returns the room number of a room ID
+func (r *changeRecorder) roomNumber(value string) (string, bool) {
+	id64, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	id := uint(id64)
+
+	if number, ok := r.roomNumbers[id]; ok {
+		return number, true
+	}
+	var room models.Room
+	if err := r.db.Select("room_number").First(&room, id).Error; err != nil {
+		return "", false
+	}
+	r.roomNumbers[id] = room.RoomNumber
+	return room.RoomNumber, true
+}
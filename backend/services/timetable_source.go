@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+)
+
+// Adapter names stored in FeedSource.Adapter
+const (
+	AdapterICSURL   = "ics_url"   // Remote ICS files, one URL per zenturie and semester
+	AdapterLocalDir = "local_dir" // Directory of ICS files on the server
+	AdapterUpload   = "upload"    // ICS files uploaded by an admin (FeedUpload)
+	AdapterJSON     = "json"      // JSON timetable document (file or URL)
+)
+
+// Content encodings stored in FeedSource.Encoding
+const (
+	EncodingLatin1 = "latin1"
+	EncodingUTF8   = "utf-8"
+)
+
+// TimetableSource produces the raw timetable data of a tenant
+// Implementations return ICSData with either Content (ICS text) or pre-parsed Events set
+type TimetableSource interface {
+	Fetch(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, opts FetchOptions) ([]ICSData, error)
+}
+
+// FetchOptions narrows down what an adapter fetches
+type FetchOptions struct {
+	Zenturie string // Only fetch the files of this zenturie, empty for all (adapters reading a single document may ignore it)
+}
+
+// includes reports whether the files of zenturie are fetched
+func (o FetchOptions) includes(zenturie string) bool {
+	return o.Zenturie == "" || strings.EqualFold(o.Zenturie, zenturie)
+}
+
+// ErrFeedPathOutsideBase is returned for local feed paths outside config.AppConfig.FeedSourceDir
+var ErrFeedPathOutsideBase = errors.New("feed source path is outside the feed source directory")
+
+// timetableSources maps FeedSource.Adapter to its implementation
+var (
+	timetableSourcesMu sync.RWMutex
+	timetableSources   = map[string]TimetableSource{
+		AdapterICSURL:   icsURLSource{},
+		AdapterLocalDir: localDirSource{},
+		AdapterUpload:   uploadSource{},
+		AdapterJSON:     jsonSource{},
+	}
+)
+
+// RegisterTimetableSource registers (or replaces) an adapter, e.g. a fake source for offline runs
+func RegisterTimetableSource(name string, source TimetableSource) {
+	timetableSourcesMu.Lock()
+	defer timetableSourcesMu.Unlock()
+	timetableSources[name] = source
+}
+
+// GetTimetableSource returns the adapter registered for name
+func GetTimetableSource(name string) (TimetableSource, error) {
+	if name == "" {
+		name = AdapterICSURL
+	}
+	timetableSourcesMu.RLock()
+	source, ok := timetableSources[name]
+	timetableSourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown timetable source adapter: %s", name)
+	}
+	return source, nil
+}
+
+// IsValidAdapter reports whether name is a registered adapter
+func IsValidAdapter(name string) bool {
+	timetableSourcesMu.RLock()
+	defer timetableSourcesMu.RUnlock()
+	_, ok := timetableSources[name]
+	return ok
+}
+
+// ResolveFeedSourcePath returns the cleaned absolute form of a local feed path
+// Relative paths are taken relative to config.AppConfig.FeedSourceDir, paths outside of it are rejected.
+func ResolveFeedSourcePath(path string) (string, error) {
+	baseDir, err := filepath.Abs(config.AppConfig.FeedSourceDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid feed source directory: %w", err)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	path = filepath.Clean(path)
+
+	if path != baseDir && !strings.HasPrefix(path, baseDir+string(filepath.Separator)) {
+		return "", ErrFeedPathOutsideBase
+	}
+	return path, nil
+}
+
+// icsURLSource fetches remote ICS files via HTTP (see FetchICSFiles)
+type icsURLSource struct{}
+
+func (icsURLSource) Fetch(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, opts FetchOptions) ([]ICSData, error) {
+	return FetchICSFiles(ctx, tenant, source, opts)
+}
+
+// localDirSource reads all *.ics files of source.Path
+// File names follow the feed layout "<zenturie>_<semester>.ics"
+type localDirSource struct{}
+
+func (localDirSource) Fetch(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, opts FetchOptions) ([]ICSData, error) {
+	if source.Path == "" {
+		return nil, fmt.Errorf("feed source of tenant %s has no path configured", tenant.Slug)
+	}
+
+	dir, err := ResolveFeedSourcePath(source.Path)
+	if err != nil {
+		return nil, fmt.Errorf("feed source of tenant %s: %w", tenant.Slug, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ics"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ICS files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	fetchStates, err := loadFeedFetchStates(tenant.ID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load feed fetch states: %v", err)
+		fetchStates = map[string]models.FeedFetchState{}
+	}
+
+	var icsData []ICSData
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if zenturie, _, ok := parseSourceFileName(filepath.Base(file)); ok && !opts.includes(zenturie) {
+			continue
+		}
+
+		body, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("  ERROR reading %s: %v", file, err)
+			continue
+		}
+
+		fileName := filepath.Base(file)
+		data, err := newICSData(fileName, "file://"+file, body, source.Encoding, fetchStates)
+		if err != nil {
+			log.Printf("  WARNING: Skipping %s: %v", fileName, err)
+			continue
+		}
+		icsData = append(icsData, data)
+	}
+
+	log.Printf("Read %d ICS files from %s", len(icsData), dir)
+
+	if len(icsData) == 0 {
+		return nil, fmt.Errorf("no ICS files found in %s", dir)
+	}
+
+	return icsData, nil
+}
+
+// uploadSource imports the ICS files an admin uploaded for the tenant
+type uploadSource struct{}
+
+func (uploadSource) Fetch(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, opts FetchOptions) ([]ICSData, error) {
+	var uploads []models.FeedUpload
+	if err := config.DB.Where("tenant_id = ?", tenant.ID).Order("file_name ASC").Find(&uploads).Error; err != nil {
+		return nil, fmt.Errorf("failed to load uploaded files: %w", err)
+	}
+
+	if len(uploads) == 0 {
+		log.Printf("WARNING: No uploaded ICS files for tenant %s", tenant.Slug)
+		return []ICSData{}, nil
+	}
+
+	fetchStates, err := loadFeedFetchStates(tenant.ID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load feed fetch states: %v", err)
+		fetchStates = map[string]models.FeedFetchState{}
+	}
+
+	icsData := make([]ICSData, 0, len(uploads))
+	for _, upload := range uploads {
+		if !opts.includes(upload.Zenturie) {
+			continue
+		}
+		// Uploaded content is stored as UTF-8 already (see SaveFeedUpload)
+		data, err := newICSData(upload.FileName, "upload://"+upload.FileName, []byte(upload.Content), EncodingUTF8, fetchStates)
+		if err != nil {
+			log.Printf("  WARNING: Skipping upload %s: %v", upload.FileName, err)
+			continue
+		}
+		data.Zenturie = upload.Zenturie
+		icsData = append(icsData, data)
+	}
+
+	return icsData, nil
+}
+
+// jsonSource reads a JSON timetable document from source.Path or, if empty, from source.BaseURL
+// Fields the document omits are filled by the tenant's extraction rules during import.
+// Events without a valid start/end are skipped and counted as errors of their zenturie.
+//
+//	{"events": [{"zenturie": "I24c", "uid": "...", "summary": "V I231 Algorithmen (Prof. Dr. Müller)",
+//	             "location": "A001", "start": "2025-01-13T08:00:00+01:00", "end": "..."}]}
+type jsonSource struct{}
+
+// jsonTimetable is the document read by jsonSource
+type jsonTimetable struct {
+	Events []jsonTimetableEvent `json:"events"`
+}
+
+type jsonTimetableEvent struct {
+	Zenturie    string    `json:"zenturie"`
+	UID         string    `json:"uid"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"all_day"`
+	Professor   string    `json:"professor"`
+	CourseCode  string    `json:"course_code"`
+	CourseType  string    `json:"course_type"`
+	Room        string    `json:"room"`
+	OnlineLink  string    `json:"online_link"`
+}
+
+func (jsonSource) Fetch(ctx context.Context, tenant *models.Tenant, source *models.FeedSource, opts FetchOptions) ([]ICSData, error) {
+	var (
+		body     []byte
+		location string
+		err      error
+	)
+
+	if source.Path != "" {
+		path, pathErr := ResolveFeedSourcePath(source.Path)
+		if pathErr != nil {
+			return nil, fmt.Errorf("feed source of tenant %s: %w", tenant.Slug, pathErr)
+		}
+		location = "file://" + path
+		body, err = os.ReadFile(path)
+	} else {
+		location = source.BaseURL
+		body, err = fetchURL(ctx, source.BaseURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON timetable of tenant %s: %w", tenant.Slug, err)
+	}
+
+	var doc jsonTimetable
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON timetable of tenant %s: %w", tenant.Slug, err)
+	}
+
+	fetchStates, err := loadFeedFetchStates(tenant.ID)
+	if err != nil {
+		log.Printf("WARNING: Failed to load feed fetch states: %v", err)
+		fetchStates = map[string]models.FeedFetchState{}
+	}
+
+	contentHash := hashContent(body)
+	state, known := fetchStates[location]
+	unchanged := known && state.ContentHash == contentHash
+	sourceFile := filepath.Base(location)
+
+	// Group events by zenturie, keeping document order
+	var zenturien []string
+	byZenturie := make(map[string][]TimetableEvent)
+	invalid := make(map[string]int)
+	for _, e := range doc.Events {
+		if e.Zenturie == "" || e.UID == "" {
+			continue
+		}
+		if e.Start.IsZero() || e.End.IsZero() || e.End.Before(e.Start) {
+			log.Printf("  WARNING: Skipping event %s of %s with invalid start/end", e.UID, e.Zenturie)
+			invalid[e.Zenturie]++
+			if _, ok := byZenturie[e.Zenturie]; !ok {
+				zenturien = append(zenturien, e.Zenturie)
+				byZenturie[e.Zenturie] = []TimetableEvent{}
+			}
+			continue
+		}
+
+		event := TimetableEvent{
+			UID:         e.UID,
+			Summary:     e.Summary,
+			Description: e.Description,
+			Location:    e.Location,
+			StartTime:   e.Start.UTC(),
+			EndTime:     e.End.UTC(),
+			Professor:   e.Professor,
+			CourseCode:  e.CourseCode,
+			CourseType:  e.CourseType,
+			Room:        e.Room,
+			OnlineLink:  e.OnlineLink,
+			SourceFile:  sourceFile,
+			IsAllDay:    e.AllDay,
+		}
+
+		if _, ok := byZenturie[e.Zenturie]; !ok {
+			zenturien = append(zenturien, e.Zenturie)
+		}
+		byZenturie[e.Zenturie] = append(byZenturie[e.Zenturie], event)
+	}
+
+	icsData := make([]ICSData, 0, len(zenturien))
+	for _, zenturie := range zenturien {
+		icsData = append(icsData, ICSData{
+			Zenturie:      zenturie,
+			SourceFile:    sourceFile,
+			URL:           location,
+			ContentHash:   contentHash,
+			Unchanged:     unchanged,
+			Events:        byZenturie[zenturie],
+			InvalidEvents: invalid[zenturie],
+		})
+	}
+
+	log.Printf("Read %d events for %d zenturien from %s", len(doc.Events), len(zenturien), location)
+
+	return icsData, nil
+}
+
+// newICSData builds the ICSData of a file read from disk or database
+// Files with the same content hash as the last import are marked unchanged
+func newICSData(fileName, location string, body []byte, encoding string, fetchStates map[string]models.FeedFetchState) (ICSData, error) {
+	zenturie, _, ok := parseSourceFileName(fileName)
+	if !ok {
+		return ICSData{}, fmt.Errorf("file name does not match <zenturie>_<semester>.ics")
+	}
+	if len(body) == 0 {
+		return ICSData{}, fmt.Errorf("empty file")
+	}
+
+	data := ICSData{
+		Zenturie:    zenturie,
+		SourceFile:  fileName,
+		URL:         location,
+		ContentHash: hashContent(body),
+	}
+
+	if state, known := fetchStates[location]; known && state.ContentHash == data.ContentHash {
+		data.Unchanged = true
+		return data, nil
+	}
+
+	content, err := decodeFeedContent(body, encoding)
+	if err != nil {
+		log.Printf("  WARNING: Failed to convert encoding for %s: %v", fileName, err)
+	}
+	data.Content = string(content)
+
+	return data, nil
+}
+
+// parseSourceFileName splits "I24c_3.ics" into zenturie "I24c" and semester 3
+func parseSourceFileName(fileName string) (string, int, bool) {
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	idx := strings.LastIndex(name, "_")
+	if idx <= 0 {
+		return "", 0, false
+	}
+
+	semester, err := strconv.Atoi(name[idx+1:])
+	if err != nil || semester <= 0 {
+		return "", 0, false
+	}
+
+	return name[:idx], semester, true
+}
+
+// decodeFeedContent converts raw feed bytes to UTF-8 according to the feed source encoding
+// On conversion errors the input is returned unchanged
+func decodeFeedContent(body []byte, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case EncodingUTF8, "utf8":
+		return body, nil
+	default:
+		// NAK feeds are Latin-1/ISO-8859-1
+		return convertToUTF8(body)
+	}
+}
+
+// fetchURL downloads a single document
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nora-nak/backend/config"
+	"github.com/nora-nak/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loadKnownSemesters loads the known semester files of a tenant, keyed by zenturie ID
+func loadKnownSemesters(tenantID uint) (map[uint][]int, error) {
+	var rows []models.ZenturieSemester
+	if err := config.DB.Where("tenant_id = ?", tenantID).Order("semester ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load zenturie semesters: %w", err)
+	}
+
+	known := make(map[uint][]int)
+	for _, row := range rows {
+		known[row.ZenturienID] = append(known[row.ZenturienID], row.Semester)
+	}
+	return known, nil
+}
+
+// semesterDiscoveryDue reports whether all semester files of a zenturie should be probed
+func semesterDiscoveryDue(zenturie *models.Zenturie, now time.Time) bool {
+	if zenturie.SemesterDiscoveryAt == nil {
+		return true
+	}
+
+	hours := config.AppConfig.ICSDiscoveryHours
+	if hours <= 0 {
+		hours = 24
+	}
+	return now.Sub(*zenturie.SemesterDiscoveryAt) >= time.Duration(hours)*time.Hour
+}
+
+// saveKnownSemesters records which semester files exist after a fetch
+// Found files are marked as seen, files that returned 404 are forgotten and their zenturie is
+// probed again on the next run (usually the semester rolled over). discovered lists the zenturien
+// whose semesters were all probed; failed downloads keep the discovery due for the next run.
+func saveKnownSemesters(tenantID uint, jobs []fetchJob, results []fetchResult, discovered map[uint]bool) {
+	now := time.Now()
+	failed := make(map[uint]bool)
+	rediscover := make(map[uint]bool)
+
+	for i, job := range jobs {
+		switch results[i].Outcome {
+		case fetchOK, fetchUnchanged:
+			row := models.ZenturieSemester{
+				TenantID:    tenantID,
+				ZenturienID: job.ZenturieID,
+				Semester:    job.Semester,
+				FirstSeenAt: now,
+				LastSeenAt:  now,
+			}
+			err := config.DB.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "zenturien_id"}, {Name: "semester"}},
+				DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+			}).Create(&row).Error
+			if err != nil {
+				log.Printf("WARNING: Failed to save semester %d of %s: %v", job.Semester, job.Zenturie, err)
+			}
+		case fetchNotFound:
+			result := config.DB.Where("zenturien_id = ? AND semester = ?", job.ZenturieID, job.Semester).
+				Delete(&models.ZenturieSemester{})
+			if result.Error != nil {
+				log.Printf("WARNING: Failed to remove semester %d of %s: %v", job.Semester, job.Zenturie, result.Error)
+			} else if result.RowsAffected > 0 {
+				log.Printf("Semester %d of %s no longer in feed, probing all semesters on the next run", job.Semester, job.Zenturie)
+				rediscover[job.ZenturieID] = true
+			}
+		case fetchFailed, fetchBreakerOpen:
+			failed[job.ZenturieID] = true
+		}
+	}
+
+	for zenturieID := range discovered {
+		if failed[zenturieID] || rediscover[zenturieID] {
+			continue
+		}
+		if err := config.DB.Model(&models.Zenturie{}).Where("id = ?", zenturieID).
+			Update("semester_discovery_at", now).Error; err != nil {
+			log.Printf("WARNING: Failed to save semester discovery of zenturie %d: %v", zenturieID, err)
+		}
+	}
+
+	for zenturieID := range rediscover {
+		if err := config.DB.Model(&models.Zenturie{}).Where("id = ?", zenturieID).
+			Update("semester_discovery_at", gorm.Expr("NULL")).Error; err != nil {
+			log.Printf("WARNING: Failed to reset semester discovery of zenturie %d: %v", zenturieID, err)
+		}
+	}
+}
+
+// ResetSemesterDiscovery forgets the known semester files of a tenant, e.g. after its feed URL changed
+// All semesters of every zenturie are probed on the next import.
+func ResetSemesterDiscovery(tenantID uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&models.ZenturieSemester{}).Error; err != nil {
+			return fmt.Errorf("failed to delete zenturie semesters: %w", err)
+		}
+		if err := tx.Model(&models.Zenturie{}).Where("tenant_id = ?", tenantID).
+			Update("semester_discovery_at", gorm.Expr("NULL")).Error; err != nil {
+			return fmt.Errorf("failed to reset semester discovery: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetActiveSemesters returns the highest semester with a feed file per zenturie of a tenant
+func GetActiveSemesters(tenantID uint) (map[uint]int, error) {
+	var rows []struct {
+		ZenturienID uint
+		Semester    int
+	}
+	err := config.DB.Model(&models.ZenturieSemester{}).
+		Select("zenturien_id, MAX(semester) AS semester").
+		Where("tenant_id = ?", tenantID).
+		Group("zenturien_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active semesters: %w", err)
+	}
+
+	active := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		active[row.ZenturienID] = row.Semester
+	}
+	return active, nil
+}
@@ -5,20 +5,107 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
-// LogICSImportStatistics logs ICS import statistics to a file
-func LogICSImportStatistics(filesDownloaded, eventsCreated, eventsUpdated, eventsUnchanged, errors int) error {
+// LogDir is the directory of the log files
+const LogDir = "logs"
+
+// RotatedLogDateLayout is the date suffix of rotated log files, e.g. backend_logs-2025-01-20.log
+const RotatedLogDateLayout = "2006-01-02"
+
+// RotateLogFile renames a log file last written on an earlier day to <name>-<date>.log
+func RotateLogFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	day := info.ModTime().Format(RotatedLogDateLayout)
+	if day == time.Now().Format(RotatedLogDateLayout) {
+		return nil
+	}
+
+	ext := filepath.Ext(path)
+	rotated := strings.TrimSuffix(path, ext) + "-" + day + ext
+	if _, err := os.Stat(rotated); err == nil {
+		// Already rotated that day (e.g. clock change), keep both
+		rotated = strings.TrimSuffix(path, ext) + "-" + day + "-" + time.Now().Format("150405") + ext
+	}
+	return os.Rename(path, rotated)
+}
+
+// DailyLogFile appends to a log file that is rotated on the first write of a new day
+type DailyLogFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	day  string
+}
+
+// OpenDailyLogFile opens a log file for appending, rotating it first if it is from an earlier day
+func OpenDailyLogFile(path string) (*DailyLogFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	w := &DailyLogFile{path: path}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open rotates and (re)opens the log file, caller must hold mu unless called from OpenDailyLogFile
+func (w *DailyLogFile) open() error {
+	if err := RotateLogFile(w.path); err != nil {
+		// Keep logging into the old file rather than losing messages
+		fmt.Fprintf(os.Stderr, "WARNING: Failed to rotate %s: %v\n", w.path, err)
+	}
+
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.day = time.Now().Format(RotatedLogDateLayout)
+	return nil
+}
+
+// Write implements io.Writer
+func (w *DailyLogFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if today := time.Now().Format(RotatedLogDateLayout); today != w.day {
+		w.file.Close()
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	return w.file.Write(p)
+}
+
+// LogICSImportStatistics logs ICS import statistics of a tenant to a file
+func LogICSImportStatistics(tenantSlug string, filesDownloaded, filesSkipped, eventsCreated, eventsUpdated, eventsUnchanged, eventsCancelled, errors int) error {
 	// Define log file path
-	logDir := "logs"
-	logFile := filepath.Join(logDir, "ics_data_imports.log")
+	logFile := filepath.Join(LogDir, "ics_data_imports.log")
 
 	// Ensure logs directory exists
-	if err := os.MkdirAll(logDir, 0755); err != nil {
+	if err := os.MkdirAll(LogDir, 0755); err != nil {
 		return fmt.Errorf("failed to create logs directory: %w", err)
 	}
 
+	// Start a new file every day, old files are deleted by the retention job
+	if err := RotateLogFile(logFile); err != nil {
+		log.Printf("WARNING: Failed to rotate %s: %v", logFile, err)
+	}
+
 	// Open log file in append mode
 	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -32,13 +119,16 @@ func LogICSImportStatistics(filesDownloaded, eventsCreated, eventsUpdated, event
 	// Format log entry
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	logEntry := fmt.Sprintf(
-		"[%s] ICS-Import abgeschlossen - Dateien heruntergeladen: %d | Datensätze gesamt: %d | Neu hinzugefügt: %d | Geändert: %d | Bereits vorhanden: %d | Fehler: %d\n",
+		"[%s] [%s] ICS-Import abgeschlossen - Dateien heruntergeladen: %d | Unverändert übersprungen: %d | Datensätze gesamt: %d | Neu hinzugefügt: %d | Geändert: %d | Bereits vorhanden: %d | Entfallen: %d | Fehler: %d\n",
 		timestamp,
+		tenantSlug,
 		filesDownloaded,
+		filesSkipped,
 		totalRecords,
 		eventsCreated,
 		eventsUpdated,
 		eventsUnchanged,
+		eventsCancelled,
 		errors,
 	)
 